@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Adjacency struct {
+	Radius   int
+	Diagonal bool
+	Wrap     bool
+}
+
+var (
+	FourConnected  = Adjacency{Radius: 1}
+	EightConnected = Adjacency{Radius: 1, Diagonal: true}
+)
+
+var ErrWrapStream = errors.New("wrap-around adjacency needs the whole schematic and cannot be streamed")
+
+func (a Adjacency) Validate() error {
+	if a.Radius < 1 {
+		return fmt.Errorf("invalid adjacency radius: %d", a.Radius)
+	}
+
+	return nil
+}
+
+func (a Adjacency) String() string {
+	name := "4-connected"
+	if a.Diagonal {
+		name = "8-connected"
+	}
+
+	if a.Radius != 1 {
+		name = fmt.Sprintf("%s radius %d", name, a.Radius)
+	}
+
+	if a.Wrap {
+		name += " toroidal"
+	}
+
+	return name
+}
+
+func (a Adjacency) Neighbours(n Number, width, height int) []Point {
+	var pts []Point
+	var seen map[Point]bool
+
+	if a.Wrap {
+		seen = make(map[Point]bool)
+	}
+
+	for dy := -a.Radius; dy <= a.Radius; dy++ {
+		r := a.reach(dy)
+
+		for x := n.X - r; x < n.X+n.Len+r; x++ {
+			pt, ok := a.clip(Point{X: x, Y: n.Y + dy}, width, height)
+			if !ok || n.covers(pt) {
+				continue
+			}
+
+			if seen != nil {
+				if seen[pt] {
+					continue
+				}
+
+				seen[pt] = true
+			}
+
+			pts = append(pts, pt)
+		}
+	}
+
+	return pts
+}
+
+func (a Adjacency) Around(p Point, width, height int) []Point {
+	return a.Neighbours(Number{X: p.X, Y: p.Y, Len: 1}, width, height)
+}
+
+func (a Adjacency) reach(dy int) int {
+	if a.Diagonal {
+		return a.Radius
+	}
+
+	return a.Radius - abs(dy)
+}
+
+func (a Adjacency) clip(p Point, width, height int) (Point, bool) {
+	if a.Wrap {
+		return Point{X: mod(p.X, width), Y: mod(p.Y, height)}, width > 0 && height > 0
+	}
+
+	return p, p.X >= 0 && p.X < width && p.Y >= 0 && p.Y < height
+}
+
+func (n Number) covers(p Point) bool {
+	return p.Y == n.Y && p.X >= n.X && p.X < n.X+n.Len
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+func mod(x, m int) int {
+	if m == 0 {
+		return 0
+	}
+
+	return ((x % m) + m) % m
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type Graph struct {
+	Numbers       []Number `json:"numbers"`
+	Symbols       []Symbol `json:"symbols"`
+	Edges         []Edge   `json:"edges"`
+	numberSymbols map[int][]int
+	symbolNumbers map[int][]int
+}
+
+type Edge struct {
+	Number int `json:"number"`
+	Symbol int `json:"symbol"`
+}
+
+func NewGraph(s Schematic, adj Adjacency) *Graph {
+	g := &Graph{
+		Numbers:       s.Numbers,
+		Symbols:       s.Symbols,
+		numberSymbols: make(map[int][]int),
+		symbolNumbers: make(map[int][]int),
+	}
+
+	index := make(map[Point]int, len(s.Symbols))
+	for _, sym := range s.Symbols {
+		index[Point{X: sym.X, Y: sym.Y}] = sym.ID
+	}
+
+	width, height := s.Width(), len(s.Rows)
+
+	for _, num := range s.Numbers {
+		for _, pt := range adj.Neighbours(num, width, height) {
+			id, ok := index[pt]
+			if !ok {
+				continue
+			}
+
+			g.Edges = append(g.Edges, Edge{
+				Number: num.ID,
+				Symbol: id,
+			})
+			g.numberSymbols[num.ID] = append(g.numberSymbols[num.ID], id)
+			g.symbolNumbers[id] = append(g.symbolNumbers[id], num.ID)
+		}
+	}
+
+	return g
+}
+
+func (g *Graph) Number(id int) Number {
+	return g.Numbers[id-1]
+}
+
+func (g *Graph) Symbol(id int) Symbol {
+	return g.Symbols[id-1]
+}
+
+func (g *Graph) SymbolsOf(number int) []int {
+	return g.numberSymbols[number]
+}
+
+func (g *Graph) NumbersOf(symbol int) []int {
+	return g.symbolNumbers[symbol]
+}
+
+func (g *Graph) PartNumbers() []Number {
+	var nums []Number
+
+	for _, num := range g.Numbers {
+		if len(g.numberSymbols[num.ID]) > 0 {
+			nums = append(nums, num)
+		}
+	}
+
+	return nums
+}
+
+func (g *Graph) Gears() []Gear {
+	var gs []Gear
+
+	for _, sym := range g.Symbols {
+		ids := g.symbolNumbers[sym.ID]
+
+		if sym.Value != "*" || len(ids) != 2 {
+			continue
+		}
+
+		gs = append(gs, Gear{
+			Symbol:  sym.ID,
+			Numbers: ids,
+			Ratio:   g.Number(ids[0]).Value * g.Number(ids[1]).Value,
+		})
+	}
+
+	return gs
+}
+
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+
+	sb.WriteString("graph schematic {\n")
+
+	for _, num := range g.Numbers {
+		fmt.Fprintf(&sb, "  n%d [label=\"%d\\n(%d,%d)\" shape=box];\n", num.ID, num.Value, num.X, num.Y)
+	}
+
+	for _, sym := range g.Symbols {
+		fmt.Fprintf(&sb, "  s%d [label=%q shape=circle];\n", sym.ID, sym.Value)
+	}
+
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&sb, "  n%d -- s%d;\n", edge.Number, edge.Symbol)
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+)
+
+type Index struct {
+	adj           Adjacency
+	rows          [][]byte
+	width, height int
+	numbers       map[int]Number
+	symbols       map[int]Symbol
+	numberAt      map[Point]int
+	symbolAt      map[Point]int
+	numberSymbols map[int]map[int]bool
+	symbolNumbers map[int]map[int]bool
+	partValue     map[int]int
+	gearValue     map[int]int
+	nextNumber    int
+	nextSymbol    int
+	parts         int
+	gears         int
+}
+
+func NewIndex(s Schematic, adj Adjacency) *Index {
+	ix := &Index{
+		adj:           adj,
+		width:         s.Width(),
+		height:        len(s.Rows),
+		numbers:       make(map[int]Number, len(s.Numbers)),
+		symbols:       make(map[int]Symbol, len(s.Symbols)),
+		numberAt:      make(map[Point]int),
+		symbolAt:      make(map[Point]int, len(s.Symbols)),
+		numberSymbols: make(map[int]map[int]bool, len(s.Numbers)),
+		symbolNumbers: make(map[int]map[int]bool, len(s.Symbols)),
+		partValue:     make(map[int]int, len(s.Numbers)),
+		gearValue:     make(map[int]int),
+		nextNumber:    len(s.Numbers) + 1,
+		nextSymbol:    len(s.Symbols) + 1,
+	}
+
+	for _, line := range s.Rows {
+		row := []byte(line)
+		for len(row) < ix.width {
+			row = append(row, '.')
+		}
+
+		ix.rows = append(ix.rows, row)
+	}
+
+	for _, sym := range s.Symbols {
+		ix.addSymbol(sym)
+	}
+
+	for _, num := range s.Numbers {
+		ix.addNumber(num)
+		ix.updatePart(num.ID)
+	}
+
+	for _, sym := range s.Symbols {
+		ix.updateGear(sym.ID)
+	}
+
+	return ix
+}
+
+func (ix *Index) SumPartNumbers() int {
+	return ix.parts
+}
+
+func (ix *Index) SumGearRatio() int {
+	return ix.gears
+}
+
+func (ix *Index) Rows() []string {
+	rows := make([]string, len(ix.rows))
+
+	for idx, row := range ix.rows {
+		rows[idx] = string(row)
+	}
+
+	return rows
+}
+
+func (ix *Index) Set(x, y int, c byte) error {
+	if y < 0 || y >= ix.height || x < 0 || x >= ix.width {
+		return fmt.Errorf("cell out of range: %d,%d", x, y)
+	}
+
+	if ix.rows[y][x] == c {
+		return nil
+	}
+
+	nums := make(map[int]bool)
+	syms := make(map[int]bool)
+
+	begin, end := x, x+1
+
+	for _, pt := range []Point{{X: x - 1, Y: y}, {X: x, Y: y}, {X: x + 1, Y: y}} {
+		id, ok := ix.numberAt[pt]
+		if !ok {
+			continue
+		}
+
+		num := ix.numbers[id]
+		begin = min(begin, num.X)
+		end = max(end, num.X+num.Len)
+
+		for sym := range ix.removeNumber(id) {
+			syms[sym] = true
+		}
+	}
+
+	if id, ok := ix.symbolAt[Point{X: x, Y: y}]; ok {
+		for num := range ix.removeSymbol(id) {
+			nums[num] = true
+		}
+	}
+
+	ix.rows[y][x] = c
+
+	for _, num := range scanNumbers(ix.rows[y], begin, end, y) {
+		num.ID = ix.nextNumber
+		ix.nextNumber++
+
+		for sym := range ix.addNumber(num) {
+			syms[sym] = true
+		}
+
+		nums[num.ID] = true
+	}
+
+	if isSymbol(c) {
+		sym := Symbol{
+			ID:    ix.nextSymbol,
+			Value: string(c),
+			X:     x,
+			Y:     y,
+		}
+		ix.nextSymbol++
+
+		for num := range ix.addSymbol(sym) {
+			nums[num] = true
+		}
+
+		syms[sym.ID] = true
+	}
+
+	for id := range nums {
+		ix.updatePart(id)
+	}
+
+	for id := range syms {
+		ix.updateGear(id)
+	}
+
+	return nil
+}
+
+func (ix *Index) addNumber(num Number) map[int]bool {
+	ix.numbers[num.ID] = num
+	ix.numberSymbols[num.ID] = make(map[int]bool)
+
+	for i := 0; i < num.Len; i++ {
+		ix.numberAt[Point{X: num.X + i, Y: num.Y}] = num.ID
+	}
+
+	for _, pt := range ix.adj.Neighbours(num, ix.width, ix.height) {
+		sym, ok := ix.symbolAt[pt]
+		if !ok {
+			continue
+		}
+
+		ix.numberSymbols[num.ID][sym] = true
+		ix.symbolNumbers[sym][num.ID] = true
+	}
+
+	return ix.numberSymbols[num.ID]
+}
+
+func (ix *Index) removeNumber(id int) map[int]bool {
+	num := ix.numbers[id]
+	syms := ix.numberSymbols[id]
+
+	for sym := range syms {
+		delete(ix.symbolNumbers[sym], id)
+	}
+
+	for i := 0; i < num.Len; i++ {
+		delete(ix.numberAt, Point{X: num.X + i, Y: num.Y})
+	}
+
+	ix.parts -= ix.partValue[id]
+
+	delete(ix.partValue, id)
+	delete(ix.numberSymbols, id)
+	delete(ix.numbers, id)
+
+	return syms
+}
+
+func (ix *Index) addSymbol(sym Symbol) map[int]bool {
+	pt := Point{X: sym.X, Y: sym.Y}
+
+	ix.symbols[sym.ID] = sym
+	ix.symbolAt[pt] = sym.ID
+	ix.symbolNumbers[sym.ID] = make(map[int]bool)
+
+	for _, p := range ix.adj.Around(pt, ix.width, ix.height) {
+		num, ok := ix.numberAt[p]
+		if !ok {
+			continue
+		}
+
+		ix.symbolNumbers[sym.ID][num] = true
+		ix.numberSymbols[num][sym.ID] = true
+	}
+
+	return ix.symbolNumbers[sym.ID]
+}
+
+func (ix *Index) removeSymbol(id int) map[int]bool {
+	sym := ix.symbols[id]
+	nums := ix.symbolNumbers[id]
+
+	for num := range nums {
+		delete(ix.numberSymbols[num], id)
+	}
+
+	ix.gears -= ix.gearValue[id]
+
+	delete(ix.symbolAt, Point{X: sym.X, Y: sym.Y})
+	delete(ix.gearValue, id)
+	delete(ix.symbolNumbers, id)
+	delete(ix.symbols, id)
+
+	return nums
+}
+
+func (ix *Index) updatePart(id int) {
+	num, ok := ix.numbers[id]
+	if !ok {
+		return
+	}
+
+	var value int
+	if len(ix.numberSymbols[id]) > 0 {
+		value = num.Value
+	}
+
+	ix.parts += value - ix.partValue[id]
+	ix.partValue[id] = value
+}
+
+func (ix *Index) updateGear(id int) {
+	sym, ok := ix.symbols[id]
+	if !ok {
+		return
+	}
+
+	var value int
+	if sym.Value == "*" && len(ix.symbolNumbers[id]) == 2 {
+		value = 1
+		for num := range ix.symbolNumbers[id] {
+			value *= ix.numbers[num].Value
+		}
+	}
+
+	ix.gears += value - ix.gearValue[id]
+	ix.gearValue[id] = value
+}
+
+func scanNumbers(row []byte, begin, end, y int) []Number {
+	var nums []Number
+
+	for x := begin; x < end; x++ {
+		if !isDigit(row[x]) {
+			continue
+		}
+
+		stop := x
+		for stop < len(row) && isDigit(row[stop]) {
+			stop++
+		}
+
+		nums = append(nums, Number{
+			Value: atoi(row[x:stop]),
+			X:     x,
+			Y:     y,
+			Len:   stop - x,
+		})
+
+		x = stop - 1
+	}
+
+	return nums
+}
@@ -2,78 +2,196 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"regexp"
 	"strconv"
-	"strings"
 )
 
-type Parts map[string][]Number
+type Schematic struct {
+	Rows    []string
+	Numbers []Number
+	Symbols []Symbol
+}
 
 type Number struct {
-	Value int
-	X, Y  int
+	ID    int `json:"id"`
+	Value int `json:"value"`
+	X     int `json:"x"`
+	Y     int `json:"y"`
+	Len   int `json:"len"`
+}
+
+type Symbol struct {
+	ID    int    `json:"id"`
+	Value string `json:"value"`
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
 }
 
 type Gear struct {
+	Symbol  int
+	Numbers []int
+	Ratio   int
+}
+
+type Point struct {
 	X, Y int
 }
 
 func main() {
-	pn := SumPartNumbers("input1.txt")
+	export := flag.String("export", "", "export adjacency graph (json, dot)")
+	stream := flag.Bool("stream", false, "stream the schematic through a three row window")
+	render := flag.String("render", "", "render highlighted schematic (ansi, html, svg)")
+	hover := flag.Bool("hover", false, "include adjacency hover data when rendering")
+	connected := flag.Int("connected", 8, "neighbourhood connectivity (4, 8)")
+	radius := flag.Int("radius", 1, "neighbourhood radius")
+	wrap := flag.Bool("wrap", false, "wrap neighbourhoods around the schematic edges")
+	flag.Parse()
+
+	adj := Adjacency{
+		Radius:   *radius,
+		Diagonal: *connected == 8,
+		Wrap:     *wrap,
+	}
+
+	if *connected != 4 && *connected != 8 {
+		log.Fatalf("unknown connectivity: %v", *connected)
+	}
+
+	if err := adj.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *render != "" {
+		renderSchematic("input1.txt", *render, *hover, adj)
+
+		return
+	}
+
+	if *export != "" {
+		exportGraph("input1.txt", *export, adj)
+
+		return
+	}
+
+	if *stream {
+		streamTotals("input1.txt", adj)
+
+		return
+	}
+
+	pn := SumPartNumbers("input1.txt", adj)
 	log.Println("Part 1: Sum of part numbers:", pn)
 
-	gr := SumGearRatio("input1.txt")
+	gr := SumGearRatio("input1.txt", adj)
 	log.Println("Part 2: Sum of gear ratio:", gr)
 }
 
-func SumPartNumbers(filename string) int {
+func SumPartNumbers(filename string, adj Adjacency) int {
 	var sum int
 
-	schem, num := schematic(filename)
-	parts := scan(schem, num)
+	graph := NewGraph(schematic(filename), adj)
 
-	for _, v := range parts {
-		for _, vv := range v {
-			sum += vv.Value
-		}
+	for _, v := range graph.PartNumbers() {
+		sum += v.Value
+	}
+
+	return sum
+}
+
+func SumGearRatio(filename string, adj Adjacency) int {
+	var sum int
+
+	graph := NewGraph(schematic(filename), adj)
+
+	for _, v := range graph.Gears() {
+		sum += v.Ratio
 	}
 
 	return sum
 }
 
-func SumGearRatio(filename string) int {
-	var gs []Gear
+func exportGraph(filename, format string, adj Adjacency) {
+	graph := NewGraph(schematic(filename), adj)
 
-	schem, _ := schematic(filename)
+	switch format {
+	case "json":
+		out, err := graph.JSON()
+		if err != nil {
+			log.Fatal("unable to export graph: %w", err)
+		}
+
+		fmt.Println(string(out))
+	case "dot":
+		fmt.Print(graph.DOT())
+	default:
+		log.Fatalf("unknown export format: %v", format)
+	}
+}
 
-	for idx, line := range schem {
-		gs = append(gs, gears(line, idx)...)
+func renderSchematic(filename, format string, hover bool, adj Adjacency) {
+	var err error
+
+	schem := schematic(filename)
+	graph := NewGraph(schem, adj)
+
+	switch format {
+	case "ansi":
+		err = RenderANSI(os.Stdout, schem, graph)
+	case "html":
+		err = RenderHTML(os.Stdout, schem, graph, hover)
+	case "svg":
+		err = RenderSVG(os.Stdout, schem, graph, hover)
+	default:
+		log.Fatalf("unknown render format: %v", format)
 	}
 
-	return scanGear(schem, gs)
+	if err != nil {
+		log.Fatal("unable to render schematic: %w", err)
+	}
 }
 
-func schematic(filename string) ([]string, []Number) {
-	var num []Number
+func streamTotals(filename string, adj Adjacency) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		log.Fatal("unable to open file: %w", err)
+	}
+	defer fh.Close()
 
+	pn, gr, err := StreamTotals(fh, adj)
+	if err != nil {
+		log.Fatal("unable to stream schematic: %w", err)
+	}
+
+	log.Println("Part 1: Sum of part numbers:", pn)
+	log.Println("Part 2: Sum of gear ratio:", gr)
+}
+
+func schematic(filename string) Schematic {
 	fh, err := os.Open(filename)
 	if err != nil {
 		log.Fatal("unable to open file: %w", err)
 	}
+	defer fh.Close()
 
-	var schematic []string
+	return readSchematic(fh)
+}
+
+func readSchematic(r io.Reader) Schematic {
+	var schem Schematic
 	var i int
 
-	scanner := bufio.NewScanner(fh)
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
 
-		num = append(num, numbers(line, i)...)
-
-		schematic = append(schematic, line)
+		schem.Numbers = append(schem.Numbers, numbers(line, i, len(schem.Numbers)+1)...)
+		schem.Symbols = append(schem.Symbols, symbols(line, i, len(schem.Symbols)+1)...)
+		schem.Rows = append(schem.Rows, line)
 
 		i++
 	}
@@ -82,14 +200,14 @@ func schematic(filename string) ([]string, []Number) {
 		log.Fatal("scanner error: %w", err)
 	}
 
-	return schematic, num
+	return schem
 }
 
-func numbers(txt string, y int) []Number {
-	re := regexp.MustCompile(`\d+`)
+var numberRegexp = regexp.MustCompile(`\d+`)
 
-	matches := re.FindAllString(txt, -1)
-	location := re.FindAllStringIndex(txt, -1)
+func numbers(txt string, y, id int) []Number {
+	matches := numberRegexp.FindAllString(txt, -1)
+	location := numberRegexp.FindAllStringIndex(txt, -1)
 
 	num := make([]Number, len(matches))
 
@@ -99,161 +217,49 @@ func numbers(txt string, y int) []Number {
 			log.Fatal("unable to convert to string: %w", err)
 		}
 
+		num[idx].ID = id + idx
 		num[idx].Value = val
 		num[idx].X = location[idx][0]
 		num[idx].Y = y
+		num[idx].Len = len(v)
 	}
 
 	return num
 }
 
-func scan(schem []string, nums []Number) Parts {
-	parts := make(Parts)
-
-	for _, v := range nums {
-		startX := v.X - 1
-		startY := v.Y - 1
-
-		endX := v.X + len(strconv.Itoa(v.Value)) + 1
-		endY := v.Y + 1
-
-		if startX < 0 {
-			startX = 0
-		}
+func symbols(txt string, y, id int) []Symbol {
+	var sym []Symbol
 
-		if startY < 0 {
-			startY = 0
+	for x := 0; x < len(txt); x++ {
+		if !isSymbol(txt[x]) {
+			continue
 		}
 
-		if endX > len(schem[v.X]) {
-			endX = len(schem[v.X])
-		}
-
-		if endY > len(schem[v.Y])-1 {
-			endY = len(schem[v.Y]) - 1
-		}
-
-		before := string(schem[startY][startX:endX])
-		line := string(schem[v.Y][startX:endX])
-		after := string(schem[endY][startX:endX])
-
-		sym := symbols(before + line + after)
-
-		for _, vv := range strings.Split(sym, "") {
-			parts[vv] = append(parts[vv], Number{
-				Value: v.Value,
-				X:     v.X,
-				Y:     v.Y,
-			})
-		}
-	}
-
-	return parts
-}
-
-func scanGear(schem []string, gs []Gear) int {
-	var sum int
-
-	for _, v := range gs {
-		startX := v.X - 1
-		startY := v.Y - 1
-
-		endX := v.X + 1 + 1
-		endY := v.Y + 1
-
-		if startX < 0 {
-			startX = 0
-		}
-
-		if startY < 0 {
-			startY = 0
-		}
-
-		if endX > len(schem[0]) {
-			endX = len(schem[0])
-		}
-
-		if endY > len(schem[0])-1 {
-			endY = len(schem[0]) - 1
-		}
-
-		before := expand(schem[startY], startX, endX)
-		line := expand(schem[v.Y], startX, endX)
-		after := expand(schem[endY], startX, endX)
-
-		sum += gearRatio(fmt.Sprintf("%s:%s:%s", before, line, after))
+		sym = append(sym, Symbol{
+			ID:    id + len(sym),
+			Value: string(txt[x]),
+			X:     x,
+			Y:     y,
+		})
 	}
 
-	return sum
-}
-
-func symbols(txt string) string {
-	const PartSymbolExclusion = `0|1|2|3|4|5|6|7|8|9|\.`
-
-	re := regexp.MustCompile(PartSymbolExclusion)
-	sym := re.ReplaceAllString(txt, "")
-
 	return sym
 }
 
-func gears(txt string, id int) []Gear {
-	var gs []Gear
-
-	re := regexp.MustCompile(`\*`)
-	loc := re.FindAllStringIndex(txt, -1)
-
-	for _, v := range loc {
-		gs = append(gs, Gear{
-			X: v[0],
-			Y: id,
-		})
-	}
-
-	return gs
+func isSymbol(c byte) bool {
+	return c != '.' && !isDigit(c)
 }
 
-func gearRatio(txt string) int {
-	re := regexp.MustCompile(`\d+`)
-	matches := re.FindAllString(txt, -1)
-
-	if len(matches) != 2 {
-		return 0
-	}
-
-	sum := 1
-
-	for _, v := range matches {
-		val, err := strconv.Atoi(v)
-		if err != nil {
-			log.Fatal("unable to convert to string: %w", err)
-		}
-
-		sum *= val
-	}
-
-	return sum
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
 }
 
-func expand(txt string, begin, end int) string {
-	const num = "01234567890"
-
-	for begin > 0 {
-		if !strings.ContainsAny(string(txt[begin]), num) ||
-			!strings.ContainsAny(string(txt[begin-1]), num) {
-			break
-		}
-
-		begin--
-	}
-
-	for end < len(txt) {
-		if !strings.ContainsAny(string(txt[end-1]), num) ||
-			!strings.ContainsAny(string(txt[end]), num) {
-			break
-		}
+func (s Schematic) Width() int {
+	var width int
 
-		end++
+	for _, row := range s.Rows {
+		width = max(width, len(row))
 	}
 
-	return string(txt[begin:end])
+	return width
 }
@@ -0,0 +1,502 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPart1SumPartNumbers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		want     int
+	}{
+		{
+			name:     "demo1",
+			filename: "demo1.txt",
+			want:     4361,
+		},
+		{
+			name:     "input1",
+			filename: "input1.txt",
+			want:     536576,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sum := SumPartNumbers(tt.filename, EightConnected)
+			assert.Equal(t, tt.want, sum)
+		})
+	}
+}
+
+func TestPart2SumGearRatio(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		want     int
+	}{
+		{
+			name:     "demo1",
+			filename: "demo1.txt",
+			want:     467835,
+		},
+		{
+			name:     "input1",
+			filename: "input1.txt",
+			want:     75741499,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sum := SumGearRatio(tt.filename, EightConnected)
+			assert.Equal(t, tt.want, sum)
+		})
+	}
+}
+
+func TestGraph(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		rows    []string
+		numbers int
+		symbols int
+		edges   []Edge
+	}{
+		{
+			name:    "shared symbol",
+			rows:    []string{"12.12", "..*..", "....."},
+			numbers: 2,
+			symbols: 1,
+			edges:   []Edge{{Number: 1, Symbol: 1}, {Number: 2, Symbol: 1}},
+		},
+		{
+			name:    "two symbols",
+			rows:    []string{"#....", ".123.", "....#"},
+			numbers: 1,
+			symbols: 2,
+			edges:   []Edge{{Number: 1, Symbol: 1}, {Number: 1, Symbol: 2}},
+		},
+		{
+			name:    "isolated",
+			rows:    []string{"7....", "....+"},
+			numbers: 1,
+			symbols: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			schem := readSchematic(strings.NewReader(strings.Join(tt.rows, "\n")))
+
+			graph := NewGraph(schem, EightConnected)
+			assert.Len(t, graph.Numbers, tt.numbers)
+			assert.Len(t, graph.Symbols, tt.symbols)
+			assert.Equal(t, tt.edges, graph.Edges)
+		})
+	}
+}
+
+func TestStreamTotals(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		parts    int
+		gears    int
+	}{
+		{
+			name:     "demo1",
+			filename: "demo1.txt",
+			parts:    4361,
+			gears:    467835,
+		},
+		{
+			name:     "input1",
+			filename: "input1.txt",
+			parts:    536576,
+			gears:    75741499,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fh, err := os.Open(tt.filename)
+			require.NoError(t, err)
+			defer fh.Close()
+
+			parts, gears, err := StreamTotals(fh, EightConnected)
+			require.NoError(t, err)
+			assert.Equal(t, tt.parts, parts)
+			assert.Equal(t, tt.gears, gears)
+		})
+	}
+}
+
+func TestStreamMatchesGraph(t *testing.T) {
+	t.Parallel()
+
+	adjs := []Adjacency{
+		EightConnected,
+		FourConnected,
+		{Radius: 2, Diagonal: true},
+		{Radius: 3},
+	}
+
+	for _, adj := range adjs {
+		for seed := int64(1); seed <= 50; seed++ {
+			var buf bytes.Buffer
+
+			_, err := io.Copy(&buf, newGridReader(seed, 20, 30))
+			require.NoError(t, err)
+
+			parts, gears := totals(NewGraph(readSchematic(bytes.NewReader(buf.Bytes())), adj))
+
+			streamParts, streamGears, err := StreamTotals(&buf, adj)
+			require.NoError(t, err)
+			assert.Equal(t, parts, streamParts, "%v seed %d", adj, seed)
+			assert.Equal(t, gears, streamGears, "%v seed %d", adj, seed)
+		}
+	}
+}
+
+func TestStreamWrap(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := StreamTotals(strings.NewReader("1*1"), Adjacency{Radius: 1, Wrap: true})
+	assert.ErrorIs(t, err, ErrWrapStream)
+}
+
+func BenchmarkStreamTotals(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_, _, err := StreamTotals(newGridReader(1, 1000000, 140), EightConnected)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type gridReader struct {
+	rng   *rand.Rand
+	rows  int
+	width int
+	line  []byte
+	pos   int
+}
+
+func newGridReader(seed int64, rows, width int) *gridReader {
+	return &gridReader{
+		rng:   rand.New(rand.NewSource(seed)),
+		rows:  rows,
+		width: width,
+	}
+}
+
+func (g *gridReader) Read(p []byte) (int, error) {
+	var n int
+
+	for n < len(p) {
+		if g.pos == len(g.line) {
+			if g.rows == 0 {
+				break
+			}
+
+			g.fill()
+			g.rows--
+		}
+
+		c := copy(p[n:], g.line[g.pos:])
+		g.pos += c
+		n += c
+	}
+
+	if n == 0 {
+		return 0, io.EOF
+	}
+
+	return n, nil
+}
+
+func (g *gridReader) fill() {
+	const cells = "..........0123456789*#+$"
+
+	g.line = g.line[:0]
+	for i := 0; i < g.width; i++ {
+		g.line = append(g.line, cells[g.rng.Intn(len(cells))])
+	}
+
+	g.line = append(g.line, '\n')
+	g.pos = 0
+}
+
+func TestTokens(t *testing.T) {
+	t.Parallel()
+
+	schem := readSchematic(strings.NewReader("467..114..\n...*......\n..35..633.\n......#..."))
+	rows := tokens(schem, NewGraph(schem, EightConnected))
+
+	var classes []string
+	for _, row := range rows {
+		for _, tok := range row {
+			if tok.Class != Empty {
+				classes = append(classes, tok.Text+":"+tok.Class.String())
+			}
+		}
+	}
+
+	assert.Equal(t, []string{"467:part", "114:loose", "*:gear", "35:part", "633:part", "#:symbol"}, classes)
+}
+
+func TestRenderANSI(t *testing.T) {
+	t.Parallel()
+
+	schem := schematic("demo1.txt")
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderANSI(&buf, schem, NewGraph(schem, EightConnected)))
+
+	out := buf.String()
+
+	assert.Equal(t, len(schem.Rows), strings.Count(out, "\n"))
+	assert.True(t, strings.HasPrefix(out, "\x1b[32m467\x1b[0m\x1b[2m..\x1b[0m\x1b[31m114\x1b[0m"))
+	assert.Contains(t, out, "\x1b[1;35m*\x1b[0m")
+	assert.Contains(t, out, "\x1b[33m#\x1b[0m")
+}
+
+func TestRenderHTML(t *testing.T) {
+	t.Parallel()
+
+	schem := schematic("demo1.txt")
+	g := NewGraph(schem, EightConnected)
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderHTML(&buf, schem, g, true))
+
+	out := buf.String()
+
+	assert.Contains(t, out, ".gear { color: #6a1b9a; }")
+	assert.Contains(t, out, `<span class="part" title="#1 467 at (0,0): * (3,1)">467</span>`)
+	assert.Contains(t, out, `<span class="loose" title="#2 114 at (5,0): no adjacent symbols">114</span>`)
+	assert.Contains(t, out, `<span class="gear" title="#1 * at (3,1): 467, 35">*</span>`)
+	assert.Contains(t, out, `<span class="symbol" title="#2 # at (6,3): 633">#</span>`)
+
+	buf.Reset()
+	require.NoError(t, RenderHTML(&buf, schem, g, false))
+	assert.NotContains(t, buf.String(), "title=\"")
+
+	escaped := readSchematic(strings.NewReader("12&.\n..<3\n"))
+
+	buf.Reset()
+	require.NoError(t, RenderHTML(&buf, escaped, NewGraph(escaped, EightConnected), true))
+	assert.Contains(t, buf.String(), `<span class="symbol" title="#1 &amp; at (2,0): 12, 3">&amp;</span>`)
+	assert.Contains(t, buf.String(), `<span class="symbol" title="#2 &lt; at (2,1): 12, 3">&lt;</span>`)
+}
+
+func TestRenderSVG(t *testing.T) {
+	t.Parallel()
+
+	schem := schematic("demo1.txt")
+	g := NewGraph(schem, EightConnected)
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderSVG(&buf, schem, g, true))
+
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="160"`))
+	assert.True(t, strings.HasSuffix(out, "</svg>\n"))
+	assert.Equal(t, len(g.Numbers)+len(g.Symbols), strings.Count(out, "<g>"))
+	assert.Contains(t, out, `<g><title>#1 467 at (0,0): * (3,1)</title><rect x="0" y="0" width="30" height="16" fill="#2e7d32" fill-opacity="0.15"/>`)
+	assert.Contains(t, out, `<rect x="30" y="16" width="10" height="16" fill="#6a1b9a" fill-opacity="0.15"/><text x="30" y="28" fill="#6a1b9a" textLength="10">*</text>`)
+	assert.Contains(t, out, `<text x="50" y="12" fill="#c62828" textLength="30">114</text>`)
+
+	buf.Reset()
+	require.NoError(t, RenderSVG(&buf, schem, g, false))
+	assert.NotContains(t, buf.String(), "<title>")
+}
+
+func TestIndexSet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		x, y  int
+		value byte
+		parts int
+		gears int
+	}{
+		{name: "unchanged", x: 0, y: 0, value: '4', parts: 4361, gears: 467835},
+		{name: "remove gear", x: 3, y: 1, value: '.', parts: 4361 - 467 - 35, gears: 467835 - 16345},
+		{name: "grow number", x: 5, y: 0, value: '1', parts: 4361, gears: 467835},
+		{name: "extend number", x: 3, y: 0, value: '0', parts: 4361 - 467 + 4670, gears: 467835 - 16345 + 4670*35},
+		{name: "split number", x: 7, y: 2, value: '.', parts: 4361 - 633 + 6, gears: 467835},
+		{name: "new symbol", x: 8, y: 0, value: '$', parts: 4361 + 114, gears: 467835},
+		{name: "out of range", x: 10, y: 0, value: '.', parts: 4361, gears: 467835},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ix := NewIndex(schematic("demo1.txt"), EightConnected)
+			assert.Equal(t, 4361, ix.SumPartNumbers())
+			assert.Equal(t, 467835, ix.SumGearRatio())
+
+			_ = ix.Set(tt.x, tt.y, tt.value)
+			assert.Equal(t, tt.parts, ix.SumPartNumbers())
+			assert.Equal(t, tt.gears, ix.SumGearRatio())
+		})
+	}
+}
+
+func TestIndexMatchesGraph(t *testing.T) {
+	t.Parallel()
+
+	const cells = "..........0123456789*#+$"
+
+	adjs := []Adjacency{
+		EightConnected,
+		FourConnected,
+		{Radius: 2, Diagonal: true},
+		{Radius: 1, Diagonal: true, Wrap: true},
+	}
+
+	for _, adj := range adjs {
+		for seed := int64(1); seed <= 20; seed++ {
+			var buf bytes.Buffer
+
+			_, err := io.Copy(&buf, newGridReader(seed, 15, 20))
+			require.NoError(t, err)
+
+			ix := NewIndex(readSchematic(&buf), adj)
+			rng := rand.New(rand.NewSource(seed))
+
+			for i := 0; i < 200; i++ {
+				x, y := rng.Intn(20), rng.Intn(15)
+				require.NoError(t, ix.Set(x, y, cells[rng.Intn(len(cells))]))
+
+				parts, gears := totals(NewGraph(readSchematic(strings.NewReader(strings.Join(ix.Rows(), "\n"))), adj))
+				require.Equal(t, parts, ix.SumPartNumbers(), "%v seed %d edit %d", adj, seed, i)
+				require.Equal(t, gears, ix.SumGearRatio(), "%v seed %d edit %d", adj, seed, i)
+			}
+		}
+	}
+}
+
+func TestAdjacency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		rows  []string
+		adj   Adjacency
+		parts int
+		gears int
+	}{
+		{
+			name:  "eight connected",
+			rows:  []string{"1...2", ".*.*.", "3...4"},
+			adj:   EightConnected,
+			parts: 10,
+			gears: 3 + 8,
+		},
+		{
+			name:  "four connected",
+			rows:  []string{"1...2", ".*.*.", "3...4"},
+			adj:   FourConnected,
+			parts: 0,
+			gears: 0,
+		},
+		{
+			name:  "four connected orthogonal",
+			rows:  []string{".5...", "3*4..", ".....", "....."},
+			adj:   FourConnected,
+			parts: 12,
+			gears: 0,
+		},
+		{
+			name:  "radius two",
+			rows:  []string{"7....", ".....", "..*..", ".....", "....9"},
+			adj:   Adjacency{Radius: 2, Diagonal: true},
+			parts: 16,
+			gears: 63,
+		},
+		{
+			name:  "manhattan radius two",
+			rows:  []string{"7....", ".....", "..*..", ".....", "....9"},
+			adj:   Adjacency{Radius: 2},
+			parts: 0,
+			gears: 0,
+		},
+		{
+			name:  "toroidal",
+			rows:  []string{"*...2", ".....", "3...."},
+			adj:   Adjacency{Radius: 1, Diagonal: true, Wrap: true},
+			parts: 5,
+			gears: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			schem := readSchematic(strings.NewReader(strings.Join(tt.rows, "\n")))
+			parts, gears := totals(NewGraph(schem, tt.adj))
+			assert.Equal(t, tt.parts, parts)
+			assert.Equal(t, tt.gears, gears)
+		})
+	}
+}
+
+func totals(graph *Graph) (int, int) {
+	var parts, gears int
+
+	for _, v := range graph.PartNumbers() {
+		parts += v.Value
+	}
+
+	for _, v := range graph.Gears() {
+		gears += v.Ratio
+	}
+
+	return parts, gears
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+type Class int
+
+const (
+	Empty Class = iota
+	PartDigit
+	LooseDigit
+	SymbolCell
+	GearCell
+)
+
+func (c Class) String() string {
+	switch c {
+	case PartDigit:
+		return "part"
+	case LooseDigit:
+		return "loose"
+	case SymbolCell:
+		return "symbol"
+	case GearCell:
+		return "gear"
+	default:
+		return "empty"
+	}
+}
+
+func (c Class) colour() string {
+	switch c {
+	case PartDigit:
+		return "#2e7d32"
+	case LooseDigit:
+		return "#c62828"
+	case SymbolCell:
+		return "#f9a825"
+	case GearCell:
+		return "#6a1b9a"
+	default:
+		return "#9e9e9e"
+	}
+}
+
+func (c Class) ansi() string {
+	switch c {
+	case PartDigit:
+		return "\x1b[32m"
+	case LooseDigit:
+		return "\x1b[31m"
+	case SymbolCell:
+		return "\x1b[33m"
+	case GearCell:
+		return "\x1b[1;35m"
+	default:
+		return "\x1b[2m"
+	}
+}
+
+type token struct {
+	Text  string
+	Class Class
+	Title string
+	X, Y  int
+}
+
+func RenderANSI(w io.Writer, s Schematic, g *Graph) error {
+	const reset = "\x1b[0m"
+
+	for _, row := range tokens(s, g) {
+		var sb strings.Builder
+
+		for _, t := range row {
+			sb.WriteString(t.Class.ansi() + t.Text + reset)
+		}
+
+		if _, err := fmt.Fprintln(w, sb.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func RenderHTML(w io.Writer, s Schematic, g *Graph, hover bool) error {
+	var sb strings.Builder
+
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Schematic</title>\n<style>\n")
+	sb.WriteString("pre { font-family: monospace; line-height: 1.2; }\n")
+
+	for _, c := range []Class{Empty, PartDigit, LooseDigit, SymbolCell, GearCell} {
+		fmt.Fprintf(&sb, ".%s { color: %s; }\n", c, c.colour())
+	}
+
+	sb.WriteString(".gear { font-weight: bold; }\n</style>\n</head>\n<body>\n<pre>\n")
+
+	for _, row := range tokens(s, g) {
+		for _, t := range row {
+			title := ""
+			if hover && t.Title != "" {
+				title = fmt.Sprintf(" title=\"%s\"", html.EscapeString(t.Title))
+			}
+
+			fmt.Fprintf(&sb, "<span class=\"%s\"%s>%s</span>", t.Class, title, html.EscapeString(t.Text))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("</pre>\n</body>\n</html>\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+func RenderSVG(w io.Writer, s Schematic, g *Graph, hover bool) error {
+	const (
+		cellWidth  = 10
+		cellHeight = 16
+	)
+
+	width := s.Width()
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" font-family=\"monospace\" font-size=\"14\">\n",
+		width*cellWidth, len(s.Rows)*cellHeight)
+	sb.WriteString("<rect width=\"100%\" height=\"100%\" fill=\"#ffffff\"/>\n")
+
+	for _, row := range tokens(s, g) {
+		for _, t := range row {
+			if t.Class == Empty {
+				continue
+			}
+
+			sb.WriteString("<g>")
+
+			if hover && t.Title != "" {
+				fmt.Fprintf(&sb, "<title>%s</title>", html.EscapeString(t.Title))
+			}
+
+			fmt.Fprintf(&sb, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\" fill-opacity=\"0.15\"/>",
+				t.X*cellWidth, t.Y*cellHeight, len(t.Text)*cellWidth, cellHeight, t.Class.colour())
+			fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%d\" fill=\"%s\" textLength=\"%d\">%s</text>",
+				t.X*cellWidth, (t.Y+1)*cellHeight-4, t.Class.colour(), len(t.Text)*cellWidth, html.EscapeString(t.Text))
+			sb.WriteString("</g>\n")
+		}
+	}
+
+	sb.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+func tokens(s Schematic, g *Graph) [][]token {
+	nums := make(map[Point]Number, len(g.Numbers))
+	for _, num := range g.Numbers {
+		nums[Point{X: num.X, Y: num.Y}] = num
+	}
+
+	syms := make(map[Point]Symbol, len(g.Symbols))
+	for _, sym := range g.Symbols {
+		syms[Point{X: sym.X, Y: sym.Y}] = sym
+	}
+
+	gears := make(map[int]bool)
+	for _, gear := range g.Gears() {
+		gears[gear.Symbol] = true
+	}
+
+	rows := make([][]token, len(s.Rows))
+
+	for y, line := range s.Rows {
+		var plain strings.Builder
+		var begin int
+
+		flush := func() {
+			if plain.Len() == 0 {
+				return
+			}
+
+			rows[y] = append(rows[y], token{Text: plain.String(), Class: Empty, X: begin, Y: y})
+			plain.Reset()
+		}
+
+		for x := 0; x < len(line); x++ {
+			pt := Point{X: x, Y: y}
+
+			if num, ok := nums[pt]; ok {
+				flush()
+
+				class := LooseDigit
+				if len(g.SymbolsOf(num.ID)) > 0 {
+					class = PartDigit
+				}
+
+				rows[y] = append(rows[y], token{
+					Text:  line[x : x+num.Len],
+					Class: class,
+					Title: numberTitle(g, num),
+					X:     x,
+					Y:     y,
+				})
+				x += num.Len - 1
+
+				continue
+			}
+
+			if sym, ok := syms[pt]; ok {
+				flush()
+
+				class := SymbolCell
+				if gears[sym.ID] {
+					class = GearCell
+				}
+
+				rows[y] = append(rows[y], token{
+					Text:  sym.Value,
+					Class: class,
+					Title: symbolTitle(g, sym),
+					X:     x,
+					Y:     y,
+				})
+
+				continue
+			}
+
+			if plain.Len() == 0 {
+				begin = x
+			}
+
+			plain.WriteByte(line[x])
+		}
+
+		flush()
+	}
+
+	return rows
+}
+
+func numberTitle(g *Graph, num Number) string {
+	var adj []string
+
+	for _, id := range g.SymbolsOf(num.ID) {
+		sym := g.Symbol(id)
+		adj = append(adj, fmt.Sprintf("%s (%d,%d)", sym.Value, sym.X, sym.Y))
+	}
+
+	if len(adj) == 0 {
+		return fmt.Sprintf("#%d %d at (%d,%d): no adjacent symbols", num.ID, num.Value, num.X, num.Y)
+	}
+
+	return fmt.Sprintf("#%d %d at (%d,%d): %s", num.ID, num.Value, num.X, num.Y, strings.Join(adj, ", "))
+}
+
+func symbolTitle(g *Graph, sym Symbol) string {
+	var adj []string
+
+	for _, id := range g.NumbersOf(sym.ID) {
+		adj = append(adj, fmt.Sprint(g.Number(id).Value))
+	}
+
+	if len(adj) == 0 {
+		return fmt.Sprintf("#%d %s at (%d,%d): no adjacent numbers", sym.ID, sym.Value, sym.X, sym.Y)
+	}
+
+	return fmt.Sprintf("#%d %s at (%d,%d): %s", sym.ID, sym.Value, sym.X, sym.Y, strings.Join(adj, ", "))
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"io"
+)
+
+type Event struct {
+	Kind  EventKind
+	Value int
+	X, Y  int
+}
+
+type EventKind int
+
+const (
+	PartNumber EventKind = iota
+	GearRatio
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case PartNumber:
+		return "partnumber"
+	case GearRatio:
+		return "gearratio"
+	default:
+		return "unknown"
+	}
+}
+
+type window struct {
+	adj  Adjacency
+	rows [][]byte
+	ok   []bool
+	y    int
+}
+
+func StreamTotals(r io.Reader, adj Adjacency) (int, int, error) {
+	var parts, gears int
+
+	err := Stream(r, adj, func(e Event) {
+		switch e.Kind {
+		case PartNumber:
+			parts += e.Value
+		case GearRatio:
+			gears += e.Value
+		}
+	})
+
+	return parts, gears, err
+}
+
+func Stream(r io.Reader, adj Adjacency, emit func(Event)) error {
+	if err := adj.Validate(); err != nil {
+		return err
+	}
+
+	if adj.Wrap {
+		return ErrWrapStream
+	}
+
+	w := window{
+		adj:  adj,
+		rows: make([][]byte, 2*adj.Radius+1),
+		ok:   make([]bool, 2*adj.Radius+1),
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		w.push(scanner.Bytes(), true)
+
+		if w.ok[adj.Radius] {
+			w.process(emit)
+			w.y++
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for i := 0; i < adj.Radius; i++ {
+		w.push(nil, false)
+
+		if w.ok[adj.Radius] {
+			w.process(emit)
+			w.y++
+		}
+	}
+
+	return nil
+}
+
+func (w *window) push(line []byte, ok bool) {
+	last := len(w.rows) - 1
+	spare := w.rows[0]
+
+	copy(w.rows, w.rows[1:])
+	copy(w.ok, w.ok[1:])
+
+	w.rows[last] = append(spare[:0], line...)
+	w.ok[last] = ok
+}
+
+func (w *window) process(emit func(Event)) {
+	cur := w.rows[w.adj.Radius]
+
+	for x := 0; x < len(cur); x++ {
+		c := cur[x]
+
+		switch {
+		case isDigit(c):
+			end := x
+			for end < len(cur) && isDigit(cur[end]) {
+				end++
+			}
+
+			if w.touchesSymbol(x, end) {
+				emit(Event{
+					Kind:  PartNumber,
+					Value: atoi(cur[x:end]),
+					X:     x,
+					Y:     w.y,
+				})
+			}
+
+			x = end - 1
+		case c == '*':
+			if ratio, ok := w.gearRatio(x); ok {
+				emit(Event{
+					Kind:  GearRatio,
+					Value: ratio,
+					X:     x,
+					Y:     w.y,
+				})
+			}
+		}
+	}
+}
+
+func (w *window) touchesSymbol(begin, end int) bool {
+	for i, row := range w.rows {
+		if !w.ok[i] {
+			continue
+		}
+
+		r := w.adj.reach(i - w.adj.Radius)
+
+		for x := max(begin-r, 0); x < end+r && x < len(row); x++ {
+			if isSymbol(row[x]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (w *window) gearRatio(x int) (int, bool) {
+	var found int
+
+	ratio := 1
+
+	for i, row := range w.rows {
+		if !w.ok[i] {
+			continue
+		}
+
+		r := w.adj.reach(i - w.adj.Radius)
+		lo := max(x-r, 0)
+
+		for col := lo; col <= x+r && col < len(row); col++ {
+			if !isDigit(row[col]) {
+				continue
+			}
+
+			if col > lo && isDigit(row[col-1]) {
+				continue
+			}
+
+			begin, end := col, col
+			for begin > 0 && isDigit(row[begin-1]) {
+				begin--
+			}
+
+			for end < len(row) && isDigit(row[end]) {
+				end++
+			}
+
+			found++
+			if found > 2 {
+				return 0, false
+			}
+
+			ratio *= atoi(row[begin:end])
+		}
+	}
+
+	return ratio, found == 2
+}
+
+func atoi(b []byte) int {
+	var n int
+
+	for _, c := range b {
+		n = n*10 + int(c-'0')
+	}
+
+	return n
+}
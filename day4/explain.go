@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+type Explanation struct {
+	Cards []Provenance `json:"cards"`
+	Total *big.Int     `json:"total"`
+}
+
+type Provenance struct {
+	Card    int      `json:"card"`
+	Wins    int      `json:"wins"`
+	Copies  *big.Int `json:"copies"`
+	Sources []Source `json:"sources"`
+}
+
+type Source struct {
+	Card   int      `json:"card"`
+	Copies *big.Int `json:"copies"`
+}
+
+func Explain(cards []Card, policy Policy) (Explanation, error) {
+	order, targets, err := cascade(cards, policy)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	counts, err := copiesBig(cards, policy)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	exp := Explanation{
+		Cards: make([]Provenance, len(cards)),
+		Total: new(big.Int),
+	}
+
+	for idx, card := range cards {
+		exp.Cards[idx] = Provenance{
+			Card:    card.ID,
+			Wins:    card.Wins,
+			Copies:  counts[idx],
+			Sources: []Source{},
+		}
+
+		exp.Total.Add(exp.Total, counts[idx])
+	}
+
+	for _, idx := range order {
+		seen := make(map[int]int)
+
+		for _, target := range targets[idx] {
+			prov := &exp.Cards[target]
+
+			pos, ok := seen[target]
+			if !ok {
+				pos = len(prov.Sources)
+				seen[target] = pos
+
+				prov.Sources = append(prov.Sources, Source{
+					Card:   cards[idx].ID,
+					Copies: new(big.Int),
+				})
+			}
+
+			prov.Sources[pos].Copies.Add(prov.Sources[pos].Copies, counts[idx])
+		}
+	}
+
+	return exp, nil
+}
+
+func (e Explanation) JSON() ([]byte, error) {
+	return json.MarshalIndent(e, "", "  ")
+}
+
+func (e Explanation) DOT() string {
+	var sb strings.Builder
+
+	sb.WriteString("digraph cascade {\n")
+	sb.WriteString("  rankdir=LR;\n")
+
+	for _, prov := range e.Cards {
+		fmt.Fprintf(&sb, "  c%d [label=\"Card %d\\n%d wins\\n%s copies\" shape=box];\n",
+			prov.Card, prov.Card, prov.Wins, prov.Copies)
+	}
+
+	for _, prov := range e.Cards {
+		for _, src := range prov.Sources {
+			fmt.Fprintf(&sb, "  c%d -> c%d [label=\"%s\"];\n", src.Card, prov.Card, src.Copies)
+		}
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+func (e Explanation) String() string {
+	var sb strings.Builder
+
+	for _, prov := range e.Cards {
+		var srcs []string
+
+		for _, src := range prov.Sources {
+			srcs = append(srcs, fmt.Sprintf("%s from card %d", src.Copies, src.Card))
+		}
+
+		line := "original only"
+		if len(srcs) > 0 {
+			line = "1 original, " + strings.Join(srcs, ", ")
+		}
+
+		fmt.Fprintf(&sb, "Card %d: %s copies (%s)\n", prov.Card, prov.Copies, line)
+	}
+
+	fmt.Fprintf(&sb, "Total: %s\n", e.Total)
+
+	return sb.String()
+}
@@ -1,178 +1,331 @@
 package main
 
 import (
-	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"math"
+	"math/big"
 	"os"
-	"regexp"
-	"strconv"
-	"strings"
+
+	"github.com/alecthomas/participle/v2"
 )
 
-type Card struct {
-	ID      int
-	Wins    int
-	Score   int
-	Numbers []int
-	Winners []int
+type Policy int
+
+const (
+	Drop Policy = iota
+	Wrap
+	Fail
+)
+
+var (
+	ErrIntOverflow = errors.New("total cards overflows int")
+	ErrOffEnd      = errors.New("copy beyond end of table")
+	ErrCycle       = errors.New("wrapped copies form a cycle")
+)
+
+type Scratchcards struct {
+	Cards []Card `@@*`
 }
 
-type Winners struct {
-	ID   int
-	Card []int
+type Card struct {
+	ID      int   `"Card" @Int ":"`
+	Winners []int `@Int* "|"`
+	Numbers []int `@Int*`
+	Wins    int
 }
 
 func main() {
-	wn := SumWinningNumbers("input1.txt")
+	name := flag.String("policy", "drop", "policy for copies beyond the last card (drop, wrap, error)")
+	validate := flag.Bool("validate", false, "validate the scratchcard table")
+	scoring := flag.String("scoring", "doubling", "scoring rule (doubling, linear, fibonacci, triangular, table)")
+	table := flag.String("table", "", "comma separated points per number of wins for the table scoring rule")
+	explain := flag.String("explain", "", "explain where card copies came from (text, json, dot)")
+	whatif := flag.Int("whatif", -1, "rank cards by impact, including wins changed by up to this amount (drop policy only)")
+	flag.Parse()
+
+	policy, err := ParsePolicy(*name)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	scorer, err := ParseScorer(*scoring, *table)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *explain != "" {
+		explainCards("input1.txt", *explain, policy)
+
+		return
+	}
+
+	if *whatif >= 0 {
+		a, err := Analyse(parseCards("input1.txt"), *whatif, policy)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Print(a)
+
+		return
+	}
+
+	if *validate {
+		problems := Validate(parseCards("input1.txt"))
+		for _, p := range problems {
+			log.Println(p)
+		}
+
+		log.Println("Problems found:", len(problems))
+
+		return
+	}
+
+	wn, err := SumWinningNumbers("input1.txt", scorer)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	log.Println("Part 1: Sum of winning numbers:", wn)
 
-	tc := TotalWinningCards("input1.txt")
+	tc := TotalWinningCardsBig("input1.txt", policy)
 	log.Println("Part 2: Total cards:", tc)
 }
 
-func SumWinningNumbers(filename string) int {
+func SumWinningNumbers(filename string, scorer Scorer) (int, error) {
+	return sumScores(parseCards(filename), scorer)
+}
+
+func sumScores(cards []Card, scorer Scorer) (int, error) {
 	var sum int
 
-	for _, card := range parseCards(filename) {
-		sum += card.Score
+	for _, card := range cards {
+		score, err := scorer.Score(card.Wins)
+		if err != nil {
+			return 0, fmt.Errorf("card %d: %w", card.ID, err)
+		}
+
+		if (score > 0 && sum > math.MaxInt-score) || (score < 0 && sum < math.MinInt-score) {
+			return 0, fmt.Errorf("sum of scores at card %d: %w", card.ID, ErrScoreOverflow)
+		}
+
+		sum += score
 	}
 
-	return sum
+	return sum, nil
 }
 
-func TotalWinningCards(filename string) int {
+func TotalWinningCards(filename string, policy Policy) int {
 	var sum int
 
-	cards := parseCards(filename)
-	winners := make([]Winners, len(cards))
+	counts, err := copies(parseCards(filename), policy)
+	if err != nil {
+		log.Fatal("unable to count cards: %w", err)
+	}
 
-	for idx := range winners {
-		winners[idx].ID = idx
-		winners[idx].Card = append(winners[idx].Card, idx)
+	for _, v := range counts {
+		sum += v
 	}
 
-	for idx, card := range cards {
-		n := len(winners[idx].Card) - 1
-		i := 0
+	return sum
+}
 
-		for i <= n {
-			winners = updateWinners(winners, idx, card.Wins)
+func TotalWinningCardsBig(filename string, policy Policy) *big.Int {
+	sum := new(big.Int)
 
-			i++
-		}
+	counts, err := copiesBig(parseCards(filename), policy)
+	if err != nil {
+		log.Fatal("unable to count cards: %w", err)
 	}
 
-	for _, v := range winners {
-		sum += len(v.Card)
+	for _, v := range counts {
+		sum.Add(sum, v)
 	}
 
 	return sum
 }
 
-func updateWinners(ws []Winners, idx, wins int) []Winners {
-	i := 1
+func copies(cards []Card, policy Policy) ([]int, error) {
+	order, targets, err := cascade(cards, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]int, len(cards))
+
+	for idx := range counts {
+		counts[idx] = 1
+	}
 
-	for i <= wins {
-		newIdx := idx + i
+	for _, idx := range order {
+		for _, target := range targets[idx] {
+			if counts[target] > math.MaxInt-counts[idx] {
+				return nil, ErrIntOverflow
+			}
 
-		ws[newIdx].Card = append(ws[newIdx].Card, idx)
+			counts[target] += counts[idx]
+		}
+	}
+
+	var sum int
+	for _, v := range counts {
+		if sum > math.MaxInt-v {
+			return nil, ErrIntOverflow
+		}
 
-		i++
+		sum += v
 	}
 
-	return ws
+	return counts, nil
 }
 
-func parseCards(filename string) []Card {
-	var cards []Card
-
-	fh, err := os.Open(filename)
+func copiesBig(cards []Card, policy Policy) ([]*big.Int, error) {
+	order, targets, err := cascade(cards, policy)
 	if err != nil {
-		log.Fatal("unable to open file: %w", err)
+		return nil, err
 	}
 
-	scanner := bufio.NewScanner(fh)
-	for scanner.Scan() {
-		cards = append(cards, card(scanner.Text()))
+	counts := make([]*big.Int, len(cards))
+
+	for idx := range counts {
+		counts[idx] = big.NewInt(1)
 	}
 
-	if err := scanner.Err(); err != nil {
-		log.Fatal("scanner error: %w", err)
+	for _, idx := range order {
+		for _, target := range targets[idx] {
+			counts[target].Add(counts[target], counts[idx])
+		}
 	}
 
-	return cards
+	return counts, nil
 }
 
-func card(txt string) Card {
-	re := regexp.MustCompile(`Card\s+(\d+):(.*)`)
-	matches := re.FindStringSubmatch(txt)
-	id := toInt(matches[1])[0]
+func cascade(cards []Card, policy Policy) ([]int, [][]int, error) {
+	targets := make([][]int, len(cards))
+	degree := make([]int, len(cards))
 
-	arr := strings.SplitN(matches[2], "|", 2)
-	wins := toInt(arr[0])
-	nums := toInt(arr[1])
+	for idx, card := range cards {
+		for i := 1; i <= card.Wins; i++ {
+			target := idx + i
+
+			if target >= len(cards) {
+				switch policy {
+				case Drop:
+					continue
+				case Wrap:
+					target %= len(cards)
+				default:
+					return nil, nil, fmt.Errorf("card %d wins copy of card beyond end of table: %w", card.ID, ErrOffEnd)
+				}
+			}
 
-	c := Card{
-		ID:      id,
-		Numbers: nums,
-		Winners: wins,
+			targets[idx] = append(targets[idx], target)
+			degree[target]++
+		}
 	}
 
-	c.Wins = winners(c)
-	c.Score = score(c.Wins)
+	var order []int
 
-	return c
-}
+	for idx, v := range degree {
+		if v == 0 {
+			order = append(order, idx)
+		}
+	}
 
-func score(wins int) int {
-	switch wins {
-	case 0:
-		return 0
-	case 1:
-		return 1
-	default:
-		return power(2, wins-1)
+	for i := 0; i < len(order); i++ {
+		for _, target := range targets[order[i]] {
+			degree[target]--
+
+			if degree[target] == 0 {
+				order = append(order, target)
+			}
+		}
+	}
+
+	if len(order) != len(cards) {
+		return nil, nil, ErrCycle
 	}
+
+	return order, targets, nil
 }
 
-func toInt(txt string) []int {
-	var nums []int
+func explainCards(filename, format string, policy Policy) {
+	exp, err := Explain(parseCards(filename), policy)
+	if err != nil {
+		log.Fatal("unable to explain cards: %w", err)
+	}
 
-	for _, val := range strings.Fields(txt) {
-		num, err := strconv.Atoi(strings.TrimSpace(val))
+	switch format {
+	case "text":
+		fmt.Print(exp)
+	case "json":
+		out, err := exp.JSON()
 		if err != nil {
-			log.Fatal("unable to convert to int: %w", err)
+			log.Fatal("unable to export explanation: %w", err)
 		}
 
-		nums = append(nums, num)
+		fmt.Println(string(out))
+	case "dot":
+		fmt.Print(exp.DOT())
+	default:
+		log.Fatalf("unknown explain format: %v", format)
 	}
+}
 
-	return nums
+func ParsePolicy(txt string) (Policy, error) {
+	switch txt {
+	case "drop":
+		return Drop, nil
+	case "wrap":
+		return Wrap, nil
+	case "error":
+		return Fail, nil
+	default:
+		return Drop, fmt.Errorf("unknown policy: %v", txt)
+	}
 }
 
-func winners(c Card) int {
-	var wins int
+func (p Policy) String() string {
+	switch p {
+	case Wrap:
+		return "wrap"
+	case Fail:
+		return "error"
+	default:
+		return "drop"
+	}
+}
+
+func parseCards(filename string) []Card {
+	fh, err := os.Open(filename)
+	if err != nil {
+		log.Fatal("unable to open file: %w", err)
+	}
+	defer fh.Close()
 
-	for _, win := range c.Winners {
-		var res bool
+	cards, err := readCards(filename, fh)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		for _, num := range c.Numbers {
-			if num == win {
-				res = true
+	return cards
+}
 
-				break
-			}
-		}
+func readCards(filename string, r io.Reader) ([]Card, error) {
+	parser := participle.MustBuild[Scratchcards]()
 
-		if res {
-			wins++
-		}
+	scratchcards, err := parser.Parse(filename, r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse scratchcards: %w", err)
 	}
 
-	return wins
-}
+	for idx := range scratchcards.Cards {
+		scratchcards.Cards[idx].Wins = winners(scratchcards.Cards[idx])
+	}
 
-func power(x, y int) int {
-	return int(math.Pow(float64(x), float64(y)))
+	return scratchcards.Cards, nil
 }
@@ -0,0 +1,621 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPart1SumWinningNumbers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		want     int
+	}{
+		{
+			name:     "demo1",
+			filename: "demo1.txt",
+			want:     13,
+		},
+		{
+			name:     "input1",
+			filename: "input1.txt",
+			want:     25571,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sum, err := SumWinningNumbers(tt.filename, Doubling{})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, sum)
+		})
+	}
+}
+
+func TestPart2TotalWinningCards(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		want     int
+	}{
+		{
+			name:     "demo1",
+			filename: "demo1.txt",
+			want:     30,
+		},
+		{
+			name:     "input1",
+			filename: "input1.txt",
+			want:     8805731,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			total := TotalWinningCards(tt.filename, Drop)
+			assert.Equal(t, tt.want, total)
+
+			totalBig := TotalWinningCardsBig(tt.filename, Drop)
+			assert.Equal(t, int64(tt.want), totalBig.Int64())
+		})
+	}
+}
+
+func TestCopiesMatchesSimulation(t *testing.T) {
+	t.Parallel()
+
+	for seed := int64(1); seed <= 100; seed++ {
+		cards := generateCards(seed, 12, 4)
+
+		counts, err := copies(cards, Drop)
+		assert.NoError(t, err)
+		assert.Equal(t, simulate(cards), counts, "seed %d", seed)
+	}
+}
+
+func TestCopiesOverflow(t *testing.T) {
+	t.Parallel()
+
+	cards := make([]Card, 100)
+	for idx := range cards {
+		cards[idx].Wins = len(cards) - idx - 1
+	}
+
+	_, err := copies(cards, Drop)
+	assert.ErrorIs(t, err, ErrIntOverflow)
+
+	counts, err := copiesBig(cards, Drop)
+	assert.NoError(t, err)
+
+	sum := new(big.Int)
+	for _, v := range counts {
+		sum.Add(sum, v)
+	}
+
+	want := new(big.Int).Lsh(big.NewInt(1), 100)
+	want.Sub(want, big.NewInt(1))
+	assert.Equal(t, want, sum)
+}
+
+func BenchmarkCopies(b *testing.B) {
+	cards := generateCards(1, 50000, 1)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = copies(cards, Drop)
+	}
+}
+
+func BenchmarkCopiesBig(b *testing.B) {
+	cards := generateCards(1, 50000, 10)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = copiesBig(cards, Drop)
+	}
+}
+
+func generateCards(seed int64, n, maxWins int) []Card {
+	rng := rand.New(rand.NewSource(seed))
+	cards := make([]Card, n)
+
+	for idx := range cards {
+		cards[idx].ID = idx + 1
+		cards[idx].Wins = min(rng.Intn(maxWins+1), n-idx-1)
+	}
+
+	return cards
+}
+
+func simulate(cards []Card) []int {
+	var pile []int
+
+	counts := make([]int, len(cards))
+
+	for idx := range cards {
+		pile = append(pile, idx)
+	}
+
+	for len(pile) > 0 {
+		idx := pile[len(pile)-1]
+		pile = pile[:len(pile)-1]
+		counts[idx]++
+
+		for i := 1; i <= cards[idx].Wins; i++ {
+			pile = append(pile, idx+i)
+		}
+	}
+
+	return counts
+}
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		wins   []int
+		policy Policy
+		want   []int
+		err    error
+	}{
+		{
+			name:   "drop",
+			wins:   []int{1, 0, 2},
+			policy: Drop,
+			want:   []int{1, 2, 1},
+		},
+		{
+			name:   "wrap",
+			wins:   []int{1, 0, 2},
+			policy: Wrap,
+			want:   []int{2, 4, 1},
+		},
+		{
+			name:   "wrap cycle",
+			wins:   []int{1, 1, 1},
+			policy: Wrap,
+			err:    ErrCycle,
+		},
+		{
+			name:   "error",
+			wins:   []int{1, 0, 2},
+			policy: Fail,
+			err:    ErrOffEnd,
+		},
+		{
+			name:   "error in range",
+			wins:   []int{2, 1, 0},
+			policy: Fail,
+			want:   []int{1, 2, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cards := make([]Card, len(tt.wins))
+			for idx, v := range tt.wins {
+				cards[idx] = Card{ID: idx + 1, Wins: v}
+			}
+
+			counts, err := copies(cards, tt.policy)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, counts)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  []ProblemKind
+	}{
+		{
+			name: "valid",
+			lines: []string{
+				"Card 1: 1 2 | 1 3 4",
+				"Card 2: 5 6 | 7 8 9",
+			},
+		},
+		{
+			name: "out of range",
+			lines: []string{
+				"Card 1: 1 2 | 1 3 4",
+				"Card 2: 5 6 | 5 6 9",
+			},
+			want: []ProblemKind{OutOfRange},
+		},
+		{
+			name: "duplicates",
+			lines: []string{
+				"Card 1: 1 1 | 7 3 3",
+				"Card 2: 5 6 | 7 8 9",
+			},
+			want: []ProblemKind{DuplicateWinner, DuplicateNumber},
+		},
+		{
+			name: "identifiers",
+			lines: []string{
+				"Card 2: 1 2 | 7 3 4",
+				"Card 4: 5 6 | 7 8 9",
+				"Card 3: 5 6 | 7 8 9",
+			},
+			want: []ProblemKind{IDGap, IDGap, IDOrder},
+		},
+		{
+			name: "columns",
+			lines: []string{
+				"Card 1: 1 2 | 7 3 4",
+				"Card 2: 5 | 7 8 9 10",
+			},
+			want: []ProblemKind{ColumnCount, ColumnCount},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cards, err := readCards("", strings.NewReader(strings.Join(tt.lines, "\n")))
+			assert.NoError(t, err)
+
+			var kinds []ProblemKind
+			for _, p := range Validate(cards) {
+				kinds = append(kinds, p.Kind)
+			}
+
+			assert.Equal(t, tt.want, kinds)
+		})
+	}
+}
+
+func TestScorer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		rule  string
+		table string
+		want  []int
+	}{
+		{
+			name: "doubling",
+			rule: "doubling",
+			want: []int{0, 1, 2, 4, 8, 16, 32},
+		},
+		{
+			name: "linear",
+			rule: "linear",
+			want: []int{0, 1, 2, 3, 4, 5, 6},
+		},
+		{
+			name: "fibonacci",
+			rule: "fibonacci",
+			want: []int{0, 1, 1, 2, 3, 5, 8},
+		},
+		{
+			name: "triangular",
+			rule: "triangular",
+			want: []int{0, 1, 3, 6, 10, 15, 21},
+		},
+		{
+			name:  "table",
+			rule:  "table",
+			table: "0, 2, 5, 9",
+			want:  []int{0, 2, 5, 9, 9, 9, 9},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			scorer, err := ParseScorer(tt.rule, tt.table)
+			assert.NoError(t, err)
+
+			var got []int
+			for wins := range tt.want {
+				score, err := scorer.Score(wins)
+				assert.NoError(t, err)
+
+				got = append(got, score)
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScoreOverflow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		scorer  Scorer
+		wins    int
+		want    int
+		wantErr bool
+	}{
+		{name: "doubling 53", scorer: Doubling{}, wins: 53, want: 1 << 52},
+		{name: "doubling 63", scorer: Doubling{}, wins: 63, want: 1 << 62},
+		{name: "doubling 64", scorer: Doubling{}, wins: 64, wantErr: true},
+		{name: "doubling 100", scorer: Doubling{}, wins: 100, wantErr: true},
+		{name: "fibonacci 63", scorer: Fibonacci{}, wins: 63, want: 6557470319842},
+		{name: "fibonacci 92", scorer: Fibonacci{}, wins: 92, want: 7540113804746346429},
+		{name: "fibonacci 93", scorer: Fibonacci{}, wins: 93, wantErr: true},
+		{name: "fibonacci 100", scorer: Fibonacci{}, wins: 100, wantErr: true},
+		{name: "triangular 100", scorer: Triangular{}, wins: 100, want: 5050},
+		{name: "triangular largest", scorer: Triangular{}, wins: 1<<32 - 1, want: 1<<63 - 1<<31},
+		{name: "triangular 2^32", scorer: Triangular{}, wins: 1 << 32, wantErr: true},
+		{name: "triangular max int", scorer: Triangular{}, wins: math.MaxInt, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.scorer.Score(tt.wins)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrScoreOverflow)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSumScoresOverflow(t *testing.T) {
+	t.Parallel()
+
+	cards := []Card{{ID: 1, Wins: 63}, {ID: 2, Wins: 63}}
+
+	_, err := sumScores(cards, Doubling{})
+	assert.ErrorIs(t, err, ErrScoreOverflow)
+
+	sum, err := sumScores(cards[:1], Doubling{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1<<62, sum)
+
+	_, err = sumScores([]Card{{ID: 1, Wins: 64}}, Doubling{})
+	assert.ErrorIs(t, err, ErrScoreOverflow)
+}
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	exp, err := Explain(parseCards("demo1.txt"), Drop)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(30), exp.Total)
+
+	want := []struct {
+		copies  int64
+		sources map[int]int64
+	}{
+		{copies: 1, sources: map[int]int64{}},
+		{copies: 2, sources: map[int]int64{1: 1}},
+		{copies: 4, sources: map[int]int64{1: 1, 2: 2}},
+		{copies: 8, sources: map[int]int64{1: 1, 2: 2, 3: 4}},
+		{copies: 14, sources: map[int]int64{1: 1, 3: 4, 4: 8}},
+		{copies: 1, sources: map[int]int64{}},
+	}
+
+	for idx, prov := range exp.Cards {
+		sources := make(map[int]int64)
+		for _, src := range prov.Sources {
+			sources[src.Card] = src.Copies.Int64()
+		}
+
+		assert.Equal(t, want[idx].copies, prov.Copies.Int64(), "card %d", prov.Card)
+		assert.Equal(t, want[idx].sources, sources, "card %d", prov.Card)
+	}
+}
+
+func TestReadCardsErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "missing separator",
+			input: "Card 1: 1 2 3 4 5 6",
+			want:  "cards.txt:1:20:",
+		},
+		{
+			name:  "bad identifier",
+			input: "Card 1: 1 2 | 3\nCard x: 4 | 5",
+			want:  "cards.txt:2:6:",
+		},
+		{
+			name:  "stray token",
+			input: "Card 1: 1 2 | 3 a",
+			want:  "cards.txt:1:17:",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := readCards("cards.txt", strings.NewReader(tt.input))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestWinnersMatchesNestedLoop(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 500; i++ {
+		limit := []int{10, 100, 1 << 20}[i%3]
+		c := generateCard(rng, 1+rng.Intn(50), 1+rng.Intn(50), limit)
+
+		assert.Equal(t, winnersNested(c), winners(c))
+	}
+}
+
+func BenchmarkWinners(b *testing.B) {
+	benchmarks := []struct {
+		name  string
+		limit int
+		fn    func(Card) int
+	}{
+		{name: "bitset", limit: 1000, fn: winners},
+		{name: "set", limit: 1 << 30, fn: winners},
+		{name: "nested", limit: 1000, fn: winnersNested},
+	}
+
+	for _, bm := range benchmarks {
+		bm := bm
+
+		b.Run(bm.name, func(b *testing.B) {
+			c := generateCard(rand.New(rand.NewSource(1)), 300, 500, bm.limit)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				bm.fn(c)
+			}
+		})
+	}
+}
+
+func generateCard(rng *rand.Rand, winners, numbers, limit int) Card {
+	c := Card{ID: 1}
+
+	for i := 0; i < winners; i++ {
+		c.Winners = append(c.Winners, rng.Intn(limit))
+	}
+
+	for i := 0; i < numbers; i++ {
+		c.Numbers = append(c.Numbers, rng.Intn(limit))
+	}
+
+	return c
+}
+
+func winnersNested(c Card) int {
+	var wins int
+
+	for _, win := range c.Winners {
+		for _, num := range c.Numbers {
+			if num == win {
+				wins++
+
+				break
+			}
+		}
+	}
+
+	return wins
+}
+
+func TestAnalyseMatchesRecompute(t *testing.T) {
+	t.Parallel()
+
+	for seed := int64(1); seed <= 50; seed++ {
+		cards := generateCards(seed, 15, 5)
+		a, err := Analyse(cards, 2, Drop)
+		assert.NoError(t, err)
+
+		assert.Equal(t, total(cards, -1), a.Total)
+
+		for _, imp := range a.Cards {
+			idx := imp.Card - 1
+
+			assert.Equal(t, total(cards, idx), imp.Removed, "seed %d card %d", seed, imp.Card)
+
+			for _, c := range imp.Changes {
+				changed := append([]Card(nil), cards...)
+				changed[idx].Wins = c.Wins
+
+				assert.Equal(t, total(changed, -1), c.Total, "seed %d card %d delta %d", seed, imp.Card, c.Delta)
+			}
+		}
+
+		for i := 1; i < len(a.Cards); i++ {
+			assert.True(t, a.Cards[i-1].Impact.Cmp(a.Cards[i].Impact) >= 0)
+		}
+	}
+}
+
+func TestAnalyseRejectsOtherPolicies(t *testing.T) {
+	t.Parallel()
+
+	cards := parseCards("demo1.txt")
+
+	for _, policy := range []Policy{Wrap, Fail} {
+		_, err := Analyse(cards, 1, policy)
+		assert.Error(t, err, policy.String())
+	}
+}
+
+func total(cards []Card, removed int) *big.Int {
+	counts := make([]*big.Int, len(cards))
+
+	for idx := range counts {
+		counts[idx] = big.NewInt(1)
+	}
+
+	if removed >= 0 {
+		counts[removed].SetInt64(0)
+	}
+
+	sum := new(big.Int)
+
+	for idx, card := range cards {
+		for i := 1; i <= card.Wins && idx+i < len(cards); i++ {
+			if idx+i != removed {
+				counts[idx+i].Add(counts[idx+i], counts[idx])
+			}
+		}
+
+		sum.Add(sum, counts[idx])
+	}
+
+	return sum
+}
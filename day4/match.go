@@ -0,0 +1,60 @@
+package main
+
+const bitsetLimit = 1 << 16
+
+type bitset []uint64
+
+func winners(c Card) int {
+	var wins int
+
+	if bits, ok := newBitset(c.Numbers); ok {
+		for _, win := range c.Winners {
+			if bits.has(win) {
+				wins++
+			}
+		}
+
+		return wins
+	}
+
+	set := make(map[int]bool, len(c.Numbers))
+	for _, num := range c.Numbers {
+		set[num] = true
+	}
+
+	for _, win := range c.Winners {
+		if set[win] {
+			wins++
+		}
+	}
+
+	return wins
+}
+
+func newBitset(nums []int) (bitset, bool) {
+	var high int
+
+	for _, num := range nums {
+		if num < 0 || num >= bitsetLimit {
+			return nil, false
+		}
+
+		high = max(high, num)
+	}
+
+	bits := make(bitset, high/64+1)
+
+	for _, num := range nums {
+		bits[num/64] |= 1 << (num % 64)
+	}
+
+	return bits, true
+}
+
+func (b bitset) has(num int) bool {
+	if num < 0 || num/64 >= len(b) {
+		return false
+	}
+
+	return b[num/64]&(1<<(num%64)) != 0
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrScoreOverflow = errors.New("score overflows int")
+
+type Scorer interface {
+	Score(wins int) (int, error)
+}
+
+type Doubling struct{}
+
+type Linear struct{}
+
+type Fibonacci struct{}
+
+type Triangular struct{}
+
+type Table []int
+
+func ParseScorer(name, table string) (Scorer, error) {
+	switch name {
+	case "doubling":
+		return Doubling{}, nil
+	case "linear":
+		return Linear{}, nil
+	case "fibonacci":
+		return Fibonacci{}, nil
+	case "triangular":
+		return Triangular{}, nil
+	case "table":
+		return ParseTable(table)
+	default:
+		return nil, fmt.Errorf("unknown scoring rule: %v", name)
+	}
+}
+
+func ParseTable(txt string) (Table, error) {
+	var t Table
+
+	for _, v := range strings.Split(txt, ",") {
+		num, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid score table entry %q: %w", v, err)
+		}
+
+		t = append(t, num)
+	}
+
+	return t, nil
+}
+
+func (Doubling) Score(wins int) (int, error) {
+	if wins <= 0 {
+		return 0, nil
+	}
+
+	if wins > 63 {
+		return 0, fmt.Errorf("doubling score for %d wins: %w", wins, ErrScoreOverflow)
+	}
+
+	return 1 << (wins - 1), nil
+}
+
+func (Linear) Score(wins int) (int, error) {
+	return max(wins, 0), nil
+}
+
+func (Fibonacci) Score(wins int) (int, error) {
+	if wins <= 0 {
+		return 0, nil
+	}
+
+	a, b := 0, 1
+
+	for i := 1; i < wins; i++ {
+		if b > math.MaxInt-a {
+			return 0, fmt.Errorf("fibonacci score for %d wins: %w", wins, ErrScoreOverflow)
+		}
+
+		a, b = b, a+b
+	}
+
+	return b, nil
+}
+
+func (Triangular) Score(wins int) (int, error) {
+	if wins <= 0 {
+		return 0, nil
+	}
+
+	a, b := wins, wins+1
+	if wins%2 == 0 {
+		a /= 2
+	} else {
+		b /= 2
+	}
+
+	if wins == math.MaxInt || a > math.MaxInt/b {
+		return 0, fmt.Errorf("triangular score for %d wins: %w", wins, ErrScoreOverflow)
+	}
+
+	return a * b, nil
+}
+
+// Win counts beyond the end of the table score the last entry.
+func (t Table) Score(wins int) (int, error) {
+	if len(t) == 0 || wins < 0 {
+		return 0, nil
+	}
+
+	return t[min(wins, len(t)-1)], nil
+}
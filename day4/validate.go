@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+)
+
+type Problem struct {
+	Line    int
+	Card    int
+	Kind    ProblemKind
+	Message string
+}
+
+type ProblemKind int
+
+const (
+	OutOfRange ProblemKind = iota
+	DuplicateWinner
+	DuplicateNumber
+	IDGap
+	IDOrder
+	ColumnCount
+)
+
+func (k ProblemKind) String() string {
+	switch k {
+	case OutOfRange:
+		return "outofrange"
+	case DuplicateWinner:
+		return "duplicatewinner"
+	case DuplicateNumber:
+		return "duplicatenumber"
+	case IDGap:
+		return "idgap"
+	case IDOrder:
+		return "idorder"
+	case ColumnCount:
+		return "columncount"
+	default:
+		return "unknown"
+	}
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("line %d: card %d: %s: %s", p.Line, p.Card, p.Kind, p.Message)
+}
+
+func Validate(cards []Card) []Problem {
+	var problems []Problem
+
+	add := func(idx int, kind ProblemKind, format string, a ...any) {
+		problems = append(problems, Problem{
+			Line:    idx + 1,
+			Card:    cards[idx].ID,
+			Kind:    kind,
+			Message: fmt.Sprintf(format, a...),
+		})
+	}
+
+	for idx, card := range cards {
+		if over := idx + card.Wins - (len(cards) - 1); over > 0 {
+			add(idx, OutOfRange, "%d wins but only %d cards follow, %d copies fall off the end",
+				card.Wins, len(cards)-idx-1, over)
+		}
+
+		for _, v := range duplicates(card.Winners) {
+			add(idx, DuplicateWinner, "winning number %d listed more than once", v)
+		}
+
+		for _, v := range duplicates(card.Numbers) {
+			add(idx, DuplicateNumber, "number %d listed more than once", v)
+		}
+
+		if idx == 0 {
+			if card.ID != 1 {
+				add(idx, IDGap, "expected card 1")
+			}
+
+			continue
+		}
+
+		prev := cards[idx-1]
+
+		switch {
+		case card.ID <= prev.ID:
+			add(idx, IDOrder, "follows card %d", prev.ID)
+		case card.ID != prev.ID+1:
+			add(idx, IDGap, "expected card %d", prev.ID+1)
+		}
+
+		if len(card.Winners) != len(cards[0].Winners) {
+			add(idx, ColumnCount, "%d winning numbers, expected %d", len(card.Winners), len(cards[0].Winners))
+		}
+
+		if len(card.Numbers) != len(cards[0].Numbers) {
+			add(idx, ColumnCount, "%d numbers, expected %d", len(card.Numbers), len(cards[0].Numbers))
+		}
+	}
+
+	return problems
+}
+
+func duplicates(nums []int) []int {
+	var dups []int
+
+	seen := make(map[int]int, len(nums))
+
+	for _, v := range nums {
+		seen[v]++
+
+		if seen[v] == 2 {
+			dups = append(dups, v)
+		}
+	}
+
+	return dups
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+type Analysis struct {
+	Total *big.Int
+	Cards []Impact
+}
+
+type Impact struct {
+	Card    int
+	Wins    int
+	Copies  *big.Int
+	Spawned *big.Int
+	Removed *big.Int
+	Impact  *big.Int
+	Changes []Change
+}
+
+type Change struct {
+	Delta int
+	Wins  int
+	Total *big.Int
+}
+
+// Analyse ranks cards by the cards lost from the final pile if they were
+// removed. A copy of card i becomes spawned[i] cards, so removing it loses
+// copies[i] * spawned[i] and changing its wins only changes spawned[i].
+// The suffix sums stop at the last card, so only the drop policy is
+// supported.
+func Analyse(cards []Card, k int, policy Policy) (Analysis, error) {
+	if policy != Drop {
+		return Analysis{}, fmt.Errorf("what-if analysis needs the drop policy, not %v", policy)
+	}
+
+	n := len(cards)
+
+	counts, err := copiesBig(cards, policy)
+	if err != nil {
+		return Analysis{}, fmt.Errorf("unable to count cards: %w", err)
+	}
+
+	spawned := make([]*big.Int, n)
+	suffix := make([]*big.Int, n+1)
+	suffix[n] = new(big.Int)
+
+	for idx := n - 1; idx >= 0; idx-- {
+		spawned[idx] = spawn(suffix, idx, cards[idx].Wins)
+		suffix[idx] = new(big.Int).Add(suffix[idx+1], spawned[idx])
+	}
+
+	total := new(big.Int)
+	for _, v := range counts {
+		total.Add(total, v)
+	}
+
+	a := Analysis{
+		Total: total,
+		Cards: make([]Impact, n),
+	}
+
+	for idx, card := range cards {
+		impact := new(big.Int).Mul(counts[idx], spawned[idx])
+
+		imp := Impact{
+			Card:    card.ID,
+			Wins:    card.Wins,
+			Copies:  counts[idx],
+			Spawned: spawned[idx],
+			Removed: new(big.Int).Sub(total, impact),
+			Impact:  impact,
+		}
+
+		for delta := -k; delta <= k; delta++ {
+			wins := card.Wins + delta
+			if delta == 0 || wins < 0 {
+				continue
+			}
+
+			diff := new(big.Int).Sub(spawn(suffix, idx, wins), spawned[idx])
+			diff.Mul(diff, counts[idx])
+
+			imp.Changes = append(imp.Changes, Change{
+				Delta: delta,
+				Wins:  wins,
+				Total: diff.Add(diff, total),
+			})
+		}
+
+		a.Cards[idx] = imp
+	}
+
+	sort.SliceStable(a.Cards, func(i, j int) bool {
+		return a.Cards[i].Impact.Cmp(a.Cards[j].Impact) > 0
+	})
+
+	return a, nil
+}
+
+func spawn(suffix []*big.Int, idx, wins int) *big.Int {
+	end := min(idx+1+wins, len(suffix)-1)
+
+	v := new(big.Int).Sub(suffix[idx+1], suffix[end])
+
+	return v.Add(v, big.NewInt(1))
+}
+
+func (a Analysis) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Total: %s\n", a.Total)
+
+	for rank, imp := range a.Cards {
+		var changes []string
+
+		for _, c := range imp.Changes {
+			changes = append(changes, fmt.Sprintf("%+d=%s", c.Delta, c.Total))
+		}
+
+		fmt.Fprintf(&sb, "%d. Card %d: impact %s, removed %s, copies %s, spawns %s",
+			rank+1, imp.Card, imp.Impact, imp.Removed, imp.Copies, imp.Spawned)
+
+		if len(changes) > 0 {
+			fmt.Fprintf(&sb, ", wins %s", strings.Join(changes, " "))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
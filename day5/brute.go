@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultChunk = 1 << 20
+	cancelCheck  = 1 << 12
+)
+
+type BruteOptions struct {
+	Workers  int
+	Chunk    int
+	Interval time.Duration
+	Report   func(Progress)
+}
+
+type Progress struct {
+	Done    int
+	Total   int
+	Best    int
+	Elapsed time.Duration
+}
+
+func (p Progress) Percent() float64 {
+	if p.Total == 0 {
+		return 100
+	}
+
+	return float64(p.Done) / float64(p.Total) * 100
+}
+
+func (p Progress) ETA() time.Duration {
+	if p.Done == 0 {
+		return 0
+	}
+
+	return time.Duration(float64(p.Elapsed) / float64(p.Done) * float64(p.Total-p.Done))
+}
+
+func (p Progress) String() string {
+	best := "none"
+	if p.Best != -1 {
+		best = fmt.Sprint(p.Best)
+	}
+
+	return fmt.Sprintf("%d/%d seeds (%.1f%%), eta %v, best %v",
+		p.Done, p.Total, p.Percent(), p.ETA().Round(time.Second), best)
+}
+
+func BruteLowestLocation(ctx context.Context, seeds []int, chain []Map, opts BruteOptions) (int, error) {
+	workers := opts.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	size := opts.Chunk
+	if size < 1 {
+		size = defaultChunk
+	}
+
+	ivs := seedIntervals(seeds)
+	chunks := chunk(ivs, size)
+
+	total := 0
+	for _, iv := range ivs {
+		total += iv.End - iv.Start
+	}
+
+	ix := NewIndex(chain)
+
+	var done atomic.Int64
+	var best atomic.Int64
+
+	best.Store(-1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	work := make(chan Interval)
+
+	go func() {
+		defer close(work)
+
+		for _, iv := range chunks {
+			select {
+			case work <- iv:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for iv := range work {
+				low := -1
+
+				seed := iv.Start
+				for ; seed < iv.End; seed++ {
+					if (seed-iv.Start)%cancelCheck == 0 && ctx.Err() != nil {
+						break
+					}
+
+					if loc := location(ix, seed); low == -1 || loc < low {
+						low = loc
+					}
+				}
+
+				lower(&best, low)
+				done.Add(int64(seed - iv.Start))
+			}
+		}()
+	}
+
+	start := time.Now()
+	progress := func() Progress {
+		return Progress{
+			Done:    int(done.Load()),
+			Total:   total,
+			Best:    int(best.Load()),
+			Elapsed: time.Since(start),
+		}
+	}
+
+	finished := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	if opts.Report != nil && opts.Interval > 0 {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+
+	loop:
+		for {
+			select {
+			case <-ticker.C:
+				opts.Report(progress())
+			case <-finished:
+				break loop
+			}
+		}
+	}
+
+	<-finished
+
+	if opts.Report != nil {
+		opts.Report(progress())
+	}
+
+	return int(best.Load()), ctx.Err()
+}
+
+func chunk(ivs []Interval, size int) []Interval {
+	var out []Interval
+
+	for _, iv := range ivs {
+		for start := iv.Start; start < iv.End; start += size {
+			out = append(out, Interval{Start: start, End: min(start+size, iv.End)})
+		}
+	}
+
+	return out
+}
+
+func lower(best *atomic.Int64, low int) {
+	if low == -1 {
+		return
+	}
+
+	for {
+		cur := best.Load()
+		if cur != -1 && cur <= int64(low) {
+			return
+		}
+
+		if best.CompareAndSwap(cur, int64(low)) {
+			return
+		}
+	}
+}
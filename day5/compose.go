@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+type Segment struct {
+	Start int
+	End   int
+	Delta int
+	Line  int
+}
+
+type Piecewise []Segment
+
+func (m Map) Piecewise() Piecewise {
+	var p Piecewise
+
+	rest := []Interval{{Start: 0, End: math.MaxInt}}
+
+	for idx := len(m.Ranges) - 1; idx >= 0; idx-- {
+		r := m.Ranges[idx]
+		src := Interval{Start: r.Source, End: r.Source + r.Length}
+
+		var next []Interval
+
+		for _, iv := range rest {
+			inside := Interval{Start: max(iv.Start, src.Start), End: min(iv.End, src.End)}
+			if inside.Empty() {
+				next = append(next, iv)
+
+				continue
+			}
+
+			p = append(p, Segment{
+				Start: inside.Start,
+				End:   inside.End,
+				Delta: r.Destination - r.Source,
+				Line:  r.Pos.Line,
+			})
+
+			if before := (Interval{Start: iv.Start, End: inside.Start}); !before.Empty() {
+				next = append(next, before)
+			}
+
+			if after := (Interval{Start: inside.End, End: iv.End}); !after.Empty() {
+				next = append(next, after)
+			}
+		}
+
+		rest = next
+	}
+
+	for _, iv := range rest {
+		p = append(p, Segment{Start: iv.Start, End: iv.End})
+	}
+
+	return p.normalise()
+}
+
+func (p Piecewise) Then(q Piecewise) Piecewise {
+	var out Piecewise
+
+	for _, s := range p {
+		start, end := s.Start+s.Delta, s.End+s.Delta
+
+		for idx := q.find(start); idx < len(q) && q[idx].Start < end; idx++ {
+			t := q[idx]
+
+			out = append(out, Segment{
+				Start: max(start, t.Start) - s.Delta,
+				End:   min(end, t.End) - s.Delta,
+				Delta: s.Delta + t.Delta,
+			})
+		}
+	}
+
+	return out.normalise()
+}
+
+func (p Piecewise) Lookup(num int) int {
+	idx := p.find(num)
+	if idx == len(p) || p[idx].Start > num {
+		return num
+	}
+
+	return num + p[idx].Delta
+}
+
+func (p Piecewise) Apply(ivs []Interval) []Interval {
+	var out []Interval
+
+	for _, iv := range ivs {
+		for idx := p.find(iv.Start); idx < len(p) && p[idx].Start < iv.End; idx++ {
+			out = append(out, Interval{
+				Start: max(iv.Start, p[idx].Start) + p[idx].Delta,
+				End:   min(iv.End, p[idx].End) + p[idx].Delta,
+			})
+		}
+	}
+
+	return merge(out)
+}
+
+func (p Piecewise) Min(ivs []Interval) (int, bool) {
+	low, ok := 0, false
+
+	for _, iv := range ivs {
+		for idx := p.find(iv.Start); idx < len(p) && p[idx].Start < iv.End; idx++ {
+			v := max(iv.Start, p[idx].Start) + p[idx].Delta
+
+			if !ok || v < low {
+				low, ok = v, true
+			}
+		}
+	}
+
+	return low, ok
+}
+
+func (p Piecewise) String() string {
+	var sb strings.Builder
+
+	for _, s := range p {
+		fmt.Fprintf(&sb, "[%d, %d) -> [%d, %d) %+d\n", s.Start, s.End, s.Start+s.Delta, s.End+s.Delta, s.Delta)
+	}
+
+	return sb.String()
+}
+
+func (p Piecewise) find(num int) int {
+	return sort.Search(len(p), func(i int) bool {
+		return p[i].End > num
+	})
+}
+
+func (p Piecewise) normalise() Piecewise {
+	var out Piecewise
+
+	sort.Slice(p, func(i, j int) bool {
+		return p[i].Start < p[j].Start
+	})
+
+	for _, s := range p {
+		if s.Start >= s.End {
+			continue
+		}
+
+		if n := len(out); n > 0 && out[n-1].End == s.Start && out[n-1].Delta == s.Delta && out[n-1].Line == s.Line {
+			out[n-1].End = s.End
+
+			continue
+		}
+
+		out = append(out, s)
+	}
+
+	return out
+}
+
+func compose(chain []Map) Piecewise {
+	f := Piecewise{{Start: 0, End: math.MaxInt}}
+
+	for _, m := range chain {
+		f = f.Then(m.Piecewise())
+	}
+
+	return f
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+func path(maps map[string]Map, from, to string) ([]Map, error) {
+	var chain []Map
+
+	seen := map[string]bool{from: true}
+	name := from
+
+	for name != to {
+		m, ok := maps[name]
+		if !ok {
+			return nil, fmt.Errorf("no path from %v to %v: no map from %v", from, to, name)
+		}
+
+		if seen[m.Destination] {
+			return nil, fmt.Errorf("no path from %v to %v: cycle at %v", from, to, m.Destination)
+		}
+
+		seen[m.Destination] = true
+		chain = append(chain, m)
+		name = m.Destination
+	}
+
+	return chain, nil
+}
+
+func mustPath(maps map[string]Map, from, to string) []Map {
+	chain, err := path(maps, from, to)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return chain
+}
+
+func Validate(almanac Almanac) error {
+	var errs []error
+
+	maps := make(map[string]Map, len(almanac.Maps))
+
+	for _, m := range almanac.Maps {
+		if _, ok := maps[m.Source]; ok {
+			errs = append(errs, fmt.Errorf("duplicate map from %v", m.Source))
+		}
+
+		maps[m.Source] = m
+
+		for _, o := range m.Overlaps() {
+			errs = append(errs, errors.New(o.String()))
+		}
+	}
+
+	if _, err := path(maps, "seed", "location"); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, cycle := range cycles(maps) {
+		errs = append(errs, fmt.Errorf("cycle: %v", strings.Join(cycle, " -> ")))
+	}
+
+	reachable := map[string]bool{"seed": true}
+	for name := "seed"; ; {
+		m, ok := maps[name]
+		if !ok || reachable[m.Destination] {
+			break
+		}
+
+		reachable[m.Destination] = true
+		name = m.Destination
+	}
+
+	for _, m := range almanac.Maps {
+		if !reachable[m.Source] {
+			errs = append(errs, fmt.Errorf("unreachable category: %v", m.Source))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func cycles(maps map[string]Map) [][]string {
+	var found [][]string
+
+	var names []string
+	for name := range maps {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	done := make(map[string]bool)
+
+	for _, start := range names {
+		var trail []string
+
+		pos := make(map[string]int)
+
+		for name := start; ; {
+			if done[name] {
+				break
+			}
+
+			if idx, ok := pos[name]; ok {
+				found = append(found, append(trail[idx:], name))
+
+				break
+			}
+
+			pos[name] = len(trail)
+			trail = append(trail, name)
+
+			m, ok := maps[name]
+			if !ok {
+				break
+			}
+
+			name = m.Destination
+		}
+
+		for _, name := range trail {
+			done[name] = true
+		}
+	}
+
+	return found
+}
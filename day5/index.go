@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+type Index []Piecewise
+
+type Overlap struct {
+	Map      string
+	First    Range
+	Second   Range
+	Interval Interval
+}
+
+func NewIndex(chain []Map) Index {
+	ix := make(Index, len(chain))
+
+	for idx, m := range chain {
+		ix[idx] = m.Piecewise()
+	}
+
+	return ix
+}
+
+func (ix Index) Lookup(num int) int {
+	for _, p := range ix {
+		num = p.Lookup(num)
+	}
+
+	return num
+}
+
+func (m Map) Overlaps() []Overlap {
+	var found []Overlap
+
+	ranges := append([]Range(nil), m.Ranges...)
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].Source < ranges[j].Source
+	})
+
+	for i, a := range ranges {
+		for _, b := range ranges[i+1:] {
+			if b.Source >= a.Source+a.Length {
+				break
+			}
+
+			found = append(found, Overlap{
+				Map:    m.Source + "-to-" + m.Destination,
+				First:  a,
+				Second: b,
+				Interval: Interval{
+					Start: b.Source,
+					End:   min(a.Source+a.Length, b.Source+b.Length),
+				},
+			})
+		}
+	}
+
+	return found
+}
+
+func (o Overlap) String() string {
+	return fmt.Sprintf("%s map: lines %d and %d overlap on [%d, %d)",
+		o.Map, o.First.Pos.Line, o.Second.Pos.Line, o.Interval.Start, o.Interval.End)
+}
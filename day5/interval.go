@@ -0,0 +1,66 @@
+package main
+
+import (
+	"sort"
+)
+
+type Interval struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+func (i Interval) Empty() bool {
+	return i.Start >= i.End
+}
+
+func seedIntervals(seeds []int) []Interval {
+	var ivs []Interval
+
+	for i := 0; i+1 < len(seeds); i += 2 {
+		ivs = append(ivs, Interval{
+			Start: seeds[i],
+			End:   seeds[i] + seeds[i+1],
+		})
+	}
+
+	return merge(ivs)
+}
+
+func (m Map) Transform(ivs []Interval) []Interval {
+	return m.Piecewise().Apply(ivs)
+}
+
+func locationIntervals(chain []Map, ivs []Interval) []Interval {
+	for _, m := range chain {
+		ivs = m.Transform(ivs)
+	}
+
+	return ivs
+}
+
+func merge(ivs []Interval) []Interval {
+	var out []Interval
+
+	sorted := make([]Interval, 0, len(ivs))
+	for _, iv := range ivs {
+		if !iv.Empty() {
+			sorted = append(sorted, iv)
+		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	for _, iv := range sorted {
+		if len(out) > 0 && iv.Start <= out[len(out)-1].End {
+			out[len(out)-1].End = max(out[len(out)-1].End, iv.End)
+
+			continue
+		}
+
+		out = append(out, iv)
+	}
+
+	return out
+}
@@ -1,10 +1,18 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"time"
 
 	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
 )
 
 type Almanac struct {
@@ -19,12 +27,76 @@ type Map struct {
 }
 
 type Range struct {
+	Pos         lexer.Position
 	Destination int `@Int`
 	Source      int `@Int`
 	Length      int `@Int`
 }
 
 func main() {
+	inspect := flag.Bool("inspect", false, "print the composed seed to location function")
+	reverse := flag.Bool("reverse", false, "print the source intervals that map to a target")
+	from := flag.String("from", "", "category to reverse look up or convert from (default location for -reverse, seed for -convert)")
+	to := flag.String("to", "", "category to reverse look up or convert to (default seed for -reverse, location for -convert)")
+	target := flag.String("target", "0", "reverse lookup target value or half-open range start-end")
+	convert := flag.Bool("convert", false, "convert the target value between any two categories")
+	validate := flag.Bool("validate", false, "validate the almanac category graph")
+	trace := flag.String("trace", "", "trace a seed value or half-open range start-end to its location")
+	format := flag.String("format", "table", "trace output format (table, json)")
+	brute := flag.Bool("brute", false, "search every seed in the part 2 ranges by brute force")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of brute force workers")
+	every := flag.Duration("progress", 10*time.Second, "interval between brute force progress reports")
+	svg := flag.String("svg", "", "write an SVG of the almanac maps to this file")
+	svgSeeds := flag.Bool("svg-seeds", false, "trace the part 2 seed ranges to location in the SVG")
+	flag.Parse()
+
+	if *trace != "" {
+		traceSeeds("input1.txt", *trace, *format)
+
+		return
+	}
+
+	if *brute {
+		bruteForce("input2.txt", *workers, *every)
+
+		return
+	}
+
+	if *svg != "" {
+		renderAlmanac("input2.txt", *svg, *svgSeeds)
+
+		return
+	}
+
+	if *reverse {
+		reverseLookup("input1.txt", orDefault(*from, "location"), orDefault(*to, "seed"), *target)
+
+		return
+	}
+
+	if *inspect {
+		_, maps := parse("input1.txt")
+		fmt.Print(compose(mustPath(maps, "seed", "location")))
+
+		return
+	}
+
+	if *convert {
+		convertValue("input1.txt", orDefault(*from, "seed"), orDefault(*to, "location"), *target)
+
+		return
+	}
+
+	if *validate {
+		if err := Validate(parseAlmanac("input1.txt")); err != nil {
+			log.Fatal(err)
+		}
+
+		log.Println("Almanac is valid")
+
+		return
+	}
+
 	low := LowestLocationNumber("input1.txt", false)
 	log.Println("Part 1: Lowest location number:", low)
 
@@ -34,11 +106,20 @@ func main() {
 
 func LowestLocationNumber(filename string, pairs bool) int {
 	seeds, maps := parse(filename)
-	locs := lowestLocation(seeds, maps, pairs)
+	f := compose(mustPath(maps, "seed", "location"))
+
+	if pairs {
+		low, ok := f.Min(seedIntervals(seeds))
+		if !ok {
+			return -1
+		}
+
+		return low
+	}
 
 	low := -1
-	for _, v := range locs {
-		if low == -1 || v < low {
+	for _, seed := range seeds {
+		if v := f.Lookup(seed); low == -1 || v < low {
 			low = v
 		}
 	}
@@ -46,100 +127,162 @@ func LowestLocationNumber(filename string, pairs bool) int {
 	return low
 }
 
-func lowestLocation(seeds []int, maps map[string]Map, pairs bool) []int {
-	var locs []int
-	min := -1
+func reverseLookup(filename, from, to, target string) {
+	_, maps := parse(filename)
+
+	ivs, err := parseTarget(target)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sources, err := Reverse(maps, from, to, ivs)
+	if err != nil {
+		log.Fatal("unable to reverse lookup: %w", err)
+	}
+
+	for _, iv := range sources {
+		fmt.Printf("%s [%d, %d)\n", to, iv.Start, iv.End)
+	}
+}
+
+func traceSeeds(filename, target, format string) {
+	_, maps := parse(filename)
 
-	switch pairs {
-	case true:
-		i := 0
-		for i < len(seeds)/2 {
-			start := seeds[i*2]
-			length := seeds[i*2+1]
+	ivs, err := parseTarget(target)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-			log.Println("Checking Pairs:", start, length)
+	trace := TraceRange(mustPath(maps, "seed", "location"), ivs[0])
 
-			for j := 0; j < length; j++ {
-				loc := location(maps, start+j)
-				if loc < min || min == -1 {
-					min = loc
-				}
+	switch format {
+	case "table":
+		err = trace.Table(os.Stdout)
+	case "json":
+		var out []byte
 
-			}
-			i++
-		}
-		locs = append(locs, min)
+		out, err = trace.JSON()
+		fmt.Println(string(out))
 	default:
-		for _, seed := range seeds {
-			locs = append(locs, location(maps, seed))
-		}
+		log.Fatalf("unknown trace format: %v", format)
 	}
 
-	return locs
+	if err != nil {
+		log.Fatal("unable to write trace: %w", err)
+	}
 }
 
-func lookup(m Map, num int) int {
-	value := -1
+func renderAlmanac(filename, out string, withSeeds bool) {
+	seeds, maps := parse(filename)
 
-	for _, v := range m.Ranges {
-		delta := v.Destination - v.Source
-		low := v.Source
-		high := v.Source + v.Length
+	var ivs []Interval
+	if withSeeds {
+		ivs = seedIntervals(seeds)
+	}
 
-		if low > num || num >= high {
-			continue
-		}
+	f, err := os.Create(out)
+	if err != nil {
+		log.Fatal("unable to create svg: %w", err)
+	}
+	defer f.Close()
+
+	if err := RenderSVG(f, mustPath(maps, "seed", "location"), ivs); err != nil {
+		log.Fatal("unable to write svg: %w", err)
+	}
+}
+
+func convertValue(filename, from, to, target string) {
+	_, maps := parse(filename)
 
-		value = num + delta
+	num, err := strconv.Atoi(target)
+	if err != nil {
+		log.Fatal("invalid target: %w", err)
 	}
 
-	if value == -1 {
-		return num
+	val, err := Convert(maps, from, to, num)
+	if err != nil {
+		log.Fatal("unable to convert: %w", err)
 	}
 
-	return value
+	fmt.Println(from, num, "->", to, val)
 }
 
-func location(maps map[string]Map, seed int) int {
-	name := "seed"
+func bruteForce(filename string, workers int, every time.Duration) {
+	seeds, maps := parse(filename)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	low, err := BruteLowestLocation(ctx, seeds, mustPath(maps, "seed", "location"), BruteOptions{
+		Workers:  workers,
+		Interval: every,
+		Report: func(p Progress) {
+			log.Println("Progress:", p)
+		},
+	})
+	if err != nil {
+		log.Println("Search interrupted:", err)
+	}
 
-	num := lookup(maps[name], seed)
-	name = maps[name].Destination
+	log.Println("Brute force: Lowest location number:", low)
+}
 
-	for {
-		num = lookup(maps[name], num)
-		name = maps[name].Destination
+func location(ix Index, seed int) int {
+	return ix.Lookup(seed)
+}
 
-		if name == "location" {
-			break
-		}
+func Convert(maps map[string]Map, from, to string, num int) (int, error) {
+	chain, err := path(maps, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return NewIndex(chain).Lookup(num), nil
+}
+
+func parse(filename string) ([]int, map[string]Map) {
+	almanac := parseAlmanac(filename)
+
+	maps := make(map[string]Map, len(almanac.Maps))
+	for _, v := range almanac.Maps {
+		maps[v.Source] = v
 	}
 
-	return num
+	return almanac.Seeds, maps
 }
 
-func parse(filemame string) ([]int, map[string]Map) {
-	file, err := os.ReadFile(filemame)
+func parseAlmanac(filename string) Almanac {
+	file, err := os.ReadFile(filename)
 	if err != nil {
 		log.Fatal("unable to read file: %w", err)
 	}
 
-	input := string(file)
+	almanac, err := readAlmanac(filename, string(file))
+	if err != nil {
+		log.Fatal(err)
+	}
 
+	return almanac
+}
+
+func readAlmanac(filename, input string) (Almanac, error) {
 	parser, err := participle.Build[Almanac]()
 	if err != nil {
-		log.Fatal("unable to build parser: %w", err)
+		return Almanac{}, fmt.Errorf("unable to build parser: %w", err)
 	}
 
-	almanac, err := parser.ParseString("", input)
+	almanac, err := parser.ParseString(filename, input)
 	if err != nil {
-		log.Fatal("unable to parse almanac: %w", err)
+		return Almanac{}, fmt.Errorf("unable to parse almanac: %w", err)
 	}
 
-	maps := make(map[string]Map, len(almanac.Maps))
-	for _, v := range almanac.Maps {
-		maps[v.Source] = v
+	return *almanac, nil
+}
+
+func orDefault(v, fallback string) string {
+	if v == "" {
+		return fallback
 	}
 
-	return almanac.Seeds, maps
+	return v
 }
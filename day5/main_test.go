@@ -0,0 +1,531 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLowestLocationNumber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		pairs    bool
+		want     int
+	}{
+		{
+			name:     "demo1",
+			filename: "demo1.txt",
+			want:     35,
+		},
+		{
+			name:     "input1",
+			filename: "input1.txt",
+			want:     389056265,
+		},
+		{
+			name:     "demo1 pairs",
+			filename: "demo1.txt",
+			pairs:    true,
+			want:     46,
+		},
+		{
+			name:     "input2 pairs",
+			filename: "input2.txt",
+			pairs:    true,
+			want:     137516820,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			low := LowestLocationNumber(tt.filename, tt.pairs)
+			assert.Equal(t, tt.want, low)
+		})
+	}
+}
+
+func TestApplyMatchesBruteForce(t *testing.T) {
+	t.Parallel()
+
+	for seed := int64(1); seed <= 200; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		seeds, maps := generateAlmanac(rng, 4, 5, 60)
+		chain := mustPath(maps, "seed", "location")
+
+		var brute []Interval
+		for i := 0; i < len(seeds); i += 2 {
+			for j := seeds[i]; j < seeds[i]+seeds[i+1]; j++ {
+				loc := linearLocation(chain, j)
+				brute = append(brute, Interval{Start: loc, End: loc + 1})
+			}
+		}
+
+		assert.Equal(t, merge(brute), compose(chain).Apply(seedIntervals(seeds)), "seed %d", seed)
+	}
+}
+
+func TestIntervalsMatchBruteForce(t *testing.T) {
+	t.Parallel()
+
+	for seed := int64(1); seed <= 200; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		seeds, maps := generateAlmanac(rng, 4, 5, 60)
+		chain := mustPath(maps, "seed", "location")
+
+		var nums []int
+		for i := 0; i < len(seeds); i += 2 {
+			for j := seeds[i]; j < seeds[i]+seeds[i+1]; j++ {
+				nums = append(nums, j)
+			}
+		}
+
+		ivs := seedIntervals(seeds)
+
+		for step, m := range chain {
+			var brute []Interval
+			for idx, num := range nums {
+				nums[idx] = linearLocation([]Map{m}, num)
+				brute = append(brute, Interval{Start: nums[idx], End: nums[idx] + 1})
+			}
+
+			ivs = m.Transform(ivs)
+			assert.Equal(t, merge(brute), ivs, "seed %d map %d", seed, step)
+		}
+
+		assert.Equal(t, ivs, locationIntervals(chain, seedIntervals(seeds)), "seed %d", seed)
+	}
+}
+
+func TestComposeMatchesBruteForce(t *testing.T) {
+	t.Parallel()
+
+	for seed := int64(1); seed <= 200; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		seeds, maps := generateAlmanac(rng, 4, 5, 60)
+		chain := mustPath(maps, "seed", "location")
+		f := compose(chain)
+
+		for num := 0; num < 120; num++ {
+			assert.Equal(t, linearLocation(chain, num), f.Lookup(num), "seed %d num %d", seed, num)
+		}
+
+		low, ok := f.Min(seedIntervals(seeds))
+		assert.True(t, ok)
+		brute, err := BruteLowestLocation(context.Background(), seeds, chain, BruteOptions{Workers: 3, Chunk: 7})
+		require.NoError(t, err)
+		assert.Equal(t, brute, low, "seed %d", seed)
+	}
+}
+
+func TestBruteLowestLocation(t *testing.T) {
+	t.Parallel()
+
+	seeds, maps := parse("demo1.txt")
+	chain := mustPath(maps, "seed", "location")
+
+	var reports []Progress
+
+	low, err := BruteLowestLocation(context.Background(), seeds, chain, BruteOptions{
+		Workers:  2,
+		Chunk:    4,
+		Interval: time.Hour,
+		Report: func(p Progress) {
+			reports = append(reports, p)
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 46, low)
+	require.NotEmpty(t, reports)
+	assert.Equal(t, Progress{Done: 27, Total: 27, Best: 46}, Progress{
+		Done:  reports[len(reports)-1].Done,
+		Total: reports[len(reports)-1].Total,
+		Best:  reports[len(reports)-1].Best,
+	})
+	assert.Equal(t, 100.0, reports[len(reports)-1].Percent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var last Progress
+
+	_, err = BruteLowestLocation(ctx, []int{0, 1 << 30}, chain, BruteOptions{
+		Workers: 2,
+		Chunk:   1 << 10,
+		Report: func(p Progress) {
+			last = p
+		},
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1<<30, last.Total)
+	assert.Less(t, last.Done, last.Total)
+}
+
+func TestReverseMatchesBruteForce(t *testing.T) {
+	t.Parallel()
+
+	for seed := int64(1); seed <= 100; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		_, maps := generateAlmanac(rng, 3, 4, 40)
+
+		target := Interval{Start: rng.Intn(60), End: 0}
+		target.End = target.Start + 1 + rng.Intn(5)
+
+		sources, err := Reverse(maps, "location", "seed", []Interval{target})
+		assert.NoError(t, err)
+
+		chain := mustPath(maps, "seed", "location")
+
+		for num := 0; num < 200; num++ {
+			loc := linearLocation(chain, num)
+			want := loc >= target.Start && loc < target.End
+
+			assert.Equal(t, want, contains(sources, num), "seed %d num %d", seed, num)
+		}
+	}
+}
+
+func TestReversePartialChain(t *testing.T) {
+	t.Parallel()
+
+	_, maps := parse("demo1.txt")
+
+	sources, err := Reverse(maps, "fertilizer", "soil", []Interval{{Start: 53, End: 54}})
+	assert.NoError(t, err)
+	assert.Equal(t, []Interval{{Start: 14, End: 15}}, sources)
+
+	_, err = Reverse(maps, "seed", "location", []Interval{{Start: 0, End: 1}})
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	link := func(from, to string) Map {
+		return Map{Source: from, Destination: to, Ranges: []Range{{Destination: 0, Source: 1, Length: 1}}}
+	}
+
+	tests := []struct {
+		name string
+		maps []Map
+		want []string
+	}{
+		{
+			name: "valid",
+			maps: []Map{link("seed", "soil"), link("soil", "location")},
+		},
+		{
+			name: "missing link",
+			maps: []Map{link("seed", "soil"), link("water", "location")},
+			want: []string{
+				"no path from seed to location: no map from soil",
+				"unreachable category: water",
+			},
+		},
+		{
+			name: "cycle",
+			maps: []Map{link("seed", "soil"), link("soil", "water"), link("water", "soil")},
+			want: []string{
+				"no path from seed to location: cycle at soil",
+				"cycle: soil -> water -> soil",
+			},
+		},
+		{
+			name: "duplicate",
+			maps: []Map{link("seed", "soil"), link("seed", "location"), link("soil", "location")},
+			want: []string{
+				"duplicate map from seed",
+				"unreachable category: soil",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := Validate(Almanac{Maps: tt.maps})
+			if tt.want == nil {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.EqualError(t, err, strings.Join(tt.want, "\n"))
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	_, maps := parse("demo1.txt")
+
+	tests := []struct {
+		name     string
+		from, to string
+		num      int
+		want     int
+		err      bool
+	}{
+		{name: "seed to location", from: "seed", to: "location", num: 79, want: 82},
+		{name: "soil to humidity", from: "soil", to: "humidity", num: 81, want: 78},
+		{name: "same category", from: "water", to: "water", num: 5, want: 5},
+		{name: "backwards", from: "location", to: "seed", num: 82, err: true},
+		{name: "unknown", from: "seed", to: "colour", num: 1, err: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			val, err := Convert(maps, tt.from, tt.to, tt.num)
+			if tt.err {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, val)
+		})
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	t.Parallel()
+
+	input := `seeds: 1
+
+seed-to-soil map:
+10 0 5
+20 40 10
+30 3 4
+40 45 2
+50 60 1
+
+soil-to-location map:
+0 0 1
+`
+
+	almanac, err := readAlmanac("overlap.txt", input)
+	assert.NoError(t, err)
+
+	var got []string
+	for _, o := range almanac.Maps[0].Overlaps() {
+		got = append(got, o.String())
+	}
+
+	assert.Equal(t, []string{
+		"seed-to-soil map: lines 4 and 6 overlap on [3, 5)",
+		"seed-to-soil map: lines 5 and 7 overlap on [45, 47)",
+	}, got)
+	assert.Empty(t, almanac.Maps[1].Overlaps())
+	assert.ErrorContains(t, Validate(almanac), "lines 4 and 6 overlap")
+}
+
+func TestIndexMatchesLinearLookup(t *testing.T) {
+	t.Parallel()
+
+	for seed := int64(1); seed <= 100; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		_, maps := generateAlmanac(rng, 4, 8, 60)
+		chain := mustPath(maps, "seed", "location")
+		ix := NewIndex(chain)
+
+		for num := 0; num < 150; num++ {
+			assert.Equal(t, linearLocation(chain, num), ix.Lookup(num), "seed %d num %d", seed, num)
+		}
+	}
+}
+
+func BenchmarkLookup(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	chain := generateLargeChain(rng, 7, 5000)
+	ix := NewIndex(chain)
+
+	b.Run("linear", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			linearLocation(chain, rng.Intn(5000*1000))
+		}
+	})
+
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ix.Lookup(rng.Intn(5000 * 1000))
+		}
+	})
+}
+
+func generateLargeChain(rng *rand.Rand, maps, ranges int) []Map {
+	var chain []Map
+
+	for i := 0; i < maps; i++ {
+		m := Map{
+			Source:      fmt.Sprintf("category%d", i),
+			Destination: fmt.Sprintf("category%d", i+1),
+		}
+
+		dests := rng.Perm(ranges)
+		for j := 0; j < ranges; j++ {
+			m.Ranges = append(m.Ranges, Range{
+				Destination: dests[j] * 1000,
+				Source:      j * 1000,
+				Length:      1000,
+			})
+		}
+
+		rng.Shuffle(len(m.Ranges), func(a, b int) {
+			m.Ranges[a], m.Ranges[b] = m.Ranges[b], m.Ranges[a]
+		})
+
+		chain = append(chain, m)
+	}
+
+	return chain
+}
+
+func linearLocation(chain []Map, seed int) int {
+	num := seed
+
+	for _, m := range chain {
+		value := num
+
+		for _, v := range m.Ranges {
+			if v.Source <= num && num < v.Source+v.Length {
+				value = num + v.Destination - v.Source
+			}
+		}
+
+		num = value
+	}
+
+	return num
+}
+
+func TestTraceRange(t *testing.T) {
+	t.Parallel()
+
+	_, maps := parse("demo1.txt")
+	chain := mustPath(maps, "seed", "location")
+
+	trace := TraceRange(chain, Interval{Start: 79, End: 80})
+
+	var categories []string
+	var values, lines []int
+
+	for _, step := range trace.Steps {
+		assert.Len(t, step.Pieces, 1)
+
+		categories = append(categories, step.Category)
+		values = append(values, step.Pieces[0].To.Start)
+		lines = append(lines, step.Pieces[0].Line)
+	}
+
+	assert.Equal(t, []string{"seed", "soil", "fertilizer", "water", "light", "temperature", "humidity", "location"}, categories)
+	assert.Equal(t, []int{79, 81, 81, 81, 74, 78, 78, 82}, values)
+	assert.Equal(t, []int{0, 5, 0, 0, 20, 25, 0, 32}, lines)
+
+	split := TraceRange(chain, Interval{Start: 95, End: 102})
+	assert.Equal(t, []Piece{
+		{From: Interval{Start: 95, End: 98}, To: Interval{Start: 97, End: 100}, Line: 5},
+		{From: Interval{Start: 98, End: 100}, To: Interval{Start: 50, End: 52}, Line: 4},
+		{From: Interval{Start: 100, End: 102}, To: Interval{Start: 100, End: 102}},
+	}, split.Steps[1].Pieces)
+}
+
+func TestRenderSVG(t *testing.T) {
+	t.Parallel()
+
+	seeds, maps := parse("demo1.txt")
+	chain := mustPath(maps, "seed", "location")
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderSVG(&buf, chain, seedIntervals(seeds)))
+
+	svg := buf.String()
+
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.True(t, strings.HasSuffix(svg, "</svg>\n"))
+	assert.Equal(t, len(chain)+1, strings.Count(svg, "<rect x="))
+	assert.Contains(t, svg, ">seed</text>")
+	assert.Contains(t, svg, ">location</text>")
+	assert.Contains(t, svg, "line 5: seed [50, 98) -&gt; soil [52, 100)")
+	assert.Contains(t, svg, "seeds [79, 93): seed [79, 93) -&gt; soil [81, 95)")
+	assert.Contains(t, svg, ">lowest 46</text>")
+
+	buf.Reset()
+	require.NoError(t, RenderSVG(&buf, chain, nil))
+
+	svg = buf.String()
+
+	assert.True(t, strings.HasSuffix(svg, "</svg>\n"))
+	assert.Equal(t, len(chain)+1, strings.Count(svg, "<rect x="))
+	assert.Contains(t, svg, "line 5: seed [50, 98) -&gt; soil [52, 100)")
+	assert.NotContains(t, svg, "seeds [")
+	assert.NotContains(t, svg, "lowest")
+
+	assert.Error(t, RenderSVG(&buf, nil, nil))
+}
+
+func contains(ivs []Interval, num int) bool {
+	for _, iv := range ivs {
+		if num >= iv.Start && num < iv.End {
+			return true
+		}
+	}
+
+	return false
+}
+
+func generateAlmanac(rng *rand.Rand, categories, ranges, limit int) ([]int, map[string]Map) {
+	var seeds []int
+
+	for i := 0; i < 1+rng.Intn(3); i++ {
+		seeds = append(seeds, rng.Intn(limit), 1+rng.Intn(limit/4))
+	}
+
+	names := []string{"seed"}
+	for i := 0; i < categories; i++ {
+		names = append(names, fmt.Sprintf("category%d", i))
+	}
+	names = append(names, "location")
+
+	maps := make(map[string]Map)
+
+	for i := 0; i+1 < len(names); i++ {
+		m := Map{
+			Source:      names[i],
+			Destination: names[i+1],
+		}
+
+		for j := 0; j < 1+rng.Intn(ranges); j++ {
+			m.Ranges = append(m.Ranges, Range{
+				Destination: rng.Intn(limit),
+				Source:      rng.Intn(limit),
+				Length:      1 + rng.Intn(limit/3),
+			})
+		}
+
+		maps[m.Source] = m
+	}
+
+	return seeds, maps
+}
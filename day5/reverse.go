@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func (m Map) Reverse(ivs []Interval) []Interval {
+	return m.Piecewise().Preimage(ivs)
+}
+
+func (p Piecewise) Preimage(ivs []Interval) []Interval {
+	var out []Interval
+
+	for _, s := range p {
+		for _, iv := range ivs {
+			out = append(out, Interval{
+				Start: max(s.Start, iv.Start-s.Delta),
+				End:   min(s.End, iv.End-s.Delta),
+			})
+		}
+	}
+
+	return merge(out)
+}
+
+func Reverse(maps map[string]Map, from, to string, target []Interval) ([]Interval, error) {
+	chain, err := path(maps, to, from)
+	if err != nil {
+		return nil, err
+	}
+
+	ivs := target
+	for idx := len(chain) - 1; idx >= 0; idx-- {
+		ivs = chain[idx].Reverse(ivs)
+	}
+
+	return ivs, nil
+}
+
+func parseTarget(txt string) ([]Interval, error) {
+	start, end, found := strings.Cut(txt, "-")
+
+	low, err := strconv.Atoi(start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target %q: %w", txt, err)
+	}
+
+	if !found {
+		return []Interval{{Start: low, End: low + 1}}, nil
+	}
+
+	high, err := strconv.Atoi(end)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target %q: %w", txt, err)
+	}
+
+	return []Interval{{Start: low, End: high}}, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+const (
+	svgHeight      = 800
+	svgColumn      = 24
+	svgGap         = 160
+	svgMargin      = 40
+	svgLabelHeight = 30
+)
+
+var svgPalette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+}
+
+func RenderSVG(w io.Writer, chain []Map, seeds []Interval) error {
+	if len(chain) == 0 {
+		return fmt.Errorf("no maps to render")
+	}
+
+	scale := svgScale(chain, seeds)
+	width := 2*svgMargin + (len(chain)+1)*(svgColumn+svgGap)
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" font-family=\"sans-serif\" font-size=\"12\">\n",
+		width, svgHeight+2*svgMargin+svgLabelHeight)
+	sb.WriteString("<rect width=\"100%\" height=\"100%\" fill=\"#ffffff\"/>\n")
+
+	for idx, m := range chain {
+		x := svgX(idx)
+
+		for n, s := range m.Piecewise() {
+			if s.Start >= scale {
+				continue
+			}
+
+			colour := "#dddddd"
+			title := fmt.Sprintf("%s [%d, %d) identity", m.Source, s.Start, s.End)
+
+			if s.Line > 0 {
+				colour = svgPalette[n%len(svgPalette)]
+				title = fmt.Sprintf("line %d: %s [%d, %d) -> %s [%d, %d)",
+					s.Line, m.Source, s.Start, s.End, m.Destination, s.Start+s.Delta, s.End+s.Delta)
+			}
+
+			band(&sb, x+svgColumn, svgX(idx+1),
+				Interval{Start: s.Start, End: s.End},
+				Interval{Start: s.Start + s.Delta, End: s.End + s.Delta},
+				scale, colour, 0.35, title)
+		}
+	}
+
+	for _, iv := range seeds {
+		trace := TraceRange(chain, iv)
+
+		for idx, step := range trace.Steps[1:] {
+			for _, piece := range step.Pieces {
+				title := fmt.Sprintf("seeds [%d, %d): %s %v -> %s %v",
+					iv.Start, iv.End, trace.Steps[idx].Category, piece.From, step.Category, piece.To)
+
+				band(&sb, svgX(idx)+svgColumn, svgX(idx+1), piece.From, piece.To, scale, "#000000", 0.6, title)
+			}
+		}
+	}
+
+	if low, ok := compose(chain).Min(seeds); ok {
+		y := svgY(low, scale)
+		x := svgX(len(chain))
+
+		fmt.Fprintf(&sb, "<line x1=\"%d\" y1=\"%.2f\" x2=\"%d\" y2=\"%.2f\" stroke=\"#d62728\" stroke-width=\"2\"/>\n",
+			x-svgGap/4, y, x+svgColumn, y)
+		fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%.2f\" fill=\"#d62728\">lowest %d</text>\n", x+svgColumn+4, y+4, low)
+	}
+
+	for idx := 0; idx <= len(chain); idx++ {
+		name := chain[len(chain)-1].Destination
+		if idx < len(chain) {
+			name = chain[idx].Source
+		}
+
+		fmt.Fprintf(&sb, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"#f5f5f5\" stroke=\"#333333\"/>\n",
+			svgX(idx), svgMargin, svgColumn, svgHeight)
+		fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%d\" text-anchor=\"middle\">%s</text>\n",
+			svgX(idx)+svgColumn/2, svgMargin+svgHeight+svgLabelHeight, html.EscapeString(name))
+	}
+
+	fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%d\">0</text>\n", svgMargin/4, svgMargin+12)
+	fmt.Fprintf(&sb, "<text x=\"%d\" y=\"%d\">%d</text>\n", svgMargin/4, svgMargin+svgHeight, scale)
+
+	sb.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+func band(sb *strings.Builder, x1, x2 int, from, to Interval, scale int, colour string, opacity float64, title string) {
+	fmt.Fprintf(sb, "<polygon points=\"%d,%.2f %d,%.2f %d,%.2f %d,%.2f\" fill=\"%s\" fill-opacity=\"%.2f\"><title>%s</title></polygon>\n",
+		x1, svgY(from.Start, scale), x2, svgY(to.Start, scale),
+		x2, svgY(to.End, scale), x1, svgY(from.End, scale),
+		colour, opacity, html.EscapeString(title))
+}
+
+func svgScale(chain []Map, seeds []Interval) int {
+	scale := 1
+
+	for _, m := range chain {
+		for _, r := range m.Ranges {
+			scale = max(scale, r.Source+r.Length, r.Destination+r.Length)
+		}
+	}
+
+	for _, iv := range seeds {
+		scale = max(scale, iv.End)
+	}
+
+	return scale
+}
+
+func svgX(column int) int {
+	return svgMargin + column*(svgColumn+svgGap)
+}
+
+func svgY(value, scale int) float64 {
+	value = min(value, scale)
+
+	return svgMargin + float64(value)/float64(scale)*svgHeight
+}
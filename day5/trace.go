@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+type Trace struct {
+	Steps []Step `json:"steps"`
+}
+
+type Step struct {
+	Category string  `json:"category"`
+	Pieces   []Piece `json:"pieces"`
+}
+
+type Piece struct {
+	From Interval `json:"from"`
+	To   Interval `json:"to"`
+	Line int      `json:"line"`
+}
+
+func TraceRange(chain []Map, iv Interval) Trace {
+	var trace Trace
+
+	if len(chain) == 0 {
+		return trace
+	}
+
+	ivs := []Interval{iv}
+
+	trace.Steps = append(trace.Steps, Step{
+		Category: chain[0].Source,
+		Pieces:   []Piece{{From: iv, To: iv}},
+	})
+
+	for _, m := range chain {
+		var pieces []Piece
+		var next []Interval
+
+		p := m.Piecewise()
+
+		for _, iv := range ivs {
+			for idx := p.find(iv.Start); idx < len(p) && p[idx].Start < iv.End; idx++ {
+				s := p[idx]
+				from := Interval{Start: max(iv.Start, s.Start), End: min(iv.End, s.End)}
+				to := Interval{Start: from.Start + s.Delta, End: from.End + s.Delta}
+
+				pieces = append(pieces, Piece{From: from, To: to, Line: s.Line})
+				next = append(next, to)
+			}
+		}
+
+		trace.Steps = append(trace.Steps, Step{
+			Category: m.Destination,
+			Pieces:   pieces,
+		})
+
+		ivs = next
+	}
+
+	return trace
+}
+
+func (t Trace) JSON() ([]byte, error) {
+	return json.MarshalIndent(t, "", "  ")
+}
+
+func (t Trace) Table(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "CATEGORY\tFROM\tTO\tLINE")
+
+	for idx, step := range t.Steps {
+		for _, piece := range step.Pieces {
+			line := "identity"
+
+			switch {
+			case idx == 0:
+				line = "-"
+			case piece.Line > 0:
+				line = fmt.Sprint(piece.Line)
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", step.Category, piece.From, piece.To, line)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func (i Interval) String() string {
+	if i.End-i.Start == 1 {
+		return fmt.Sprint(i.Start)
+	}
+
+	return fmt.Sprintf("[%d, %d)", i.Start, i.End)
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"math/big"
 	"os"
 	"strconv"
 	"strings"
@@ -15,86 +18,203 @@ type Paper struct {
 	Distance []int `"Distance" ":" @Int+`
 }
 
+type Race struct {
+	Time     *big.Int
+	Distance *big.Int
+}
+
 func main() {
-	win1 := WaysToWin("input1.txt", false)
+	model := flag.String("model", "", "report winning holds under a physics model (linear, quadratic, capped, decay, phases)")
+	top := flag.Int("top", 10, "top speed of the capped model")
+	drag := flag.Int("drag", 1, "speed lost per millisecond travelled in the decay model")
+	phases := flag.String("phases", "", "comma separated duration:factor speed multipliers of the phases model")
+	records := flag.String("records", "", "print the records giving exactly ways wins in a time:ways race")
+	generate := flag.String("generate", "", "print a paper for comma separated time:ways races")
+	flag.Parse()
+
+	if *records != "" {
+		if err := reportRecords(*records); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	if *generate != "" {
+		specs, err := ParseSpecs(*generate)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		paper, err := Generate(specs)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Print(paper)
+
+		return
+	}
+
+	if *model != "" {
+		m, err := ParseModel(*model, *top, *drag, *phases)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := reportModel("input1.txt", m); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	win1, err := WaysToWin("input1.txt", false)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	log.Println("Part 1: Ways to win:", win1)
 
-	win2 := WaysToWin("input1.txt", true)
+	win2, err := WaysToWin("input1.txt", true)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	log.Println("Part 2: Ways to win:", win2)
 }
 
-func WaysToWin(filename string, combine bool) int {
-	paper := parse(filename)
+func WaysToWin(filename string, combine bool) (*big.Int, error) {
+	paper, err := parse(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	races, err := paper.Races(combine)
+	if err != nil {
+		return nil, err
+	}
+
+	total := big.NewInt(1)
+
+	for _, race := range races {
+		total.Mul(total, Ways(race.Time, race.Distance))
+	}
+
+	return total, nil
+}
+
+func (p Paper) Races(combine bool) ([]Race, error) {
+	if len(p.Time) != len(p.Distance) {
+		return nil, fmt.Errorf("paper has %d times but %d distances", len(p.Time), len(p.Distance))
+	}
 
 	if combine {
-		paper = merge(paper)
+		return []Race{{Time: concat(p.Time), Distance: concat(p.Distance)}}, nil
+	}
+
+	races := make([]Race, 0, len(p.Time))
+
+	for idx := range p.Time {
+		races = append(races, Race{
+			Time:     big.NewInt(int64(p.Time[idx])),
+			Distance: big.NewInt(int64(p.Distance[idx])),
+		})
 	}
 
-	var rs []int
-	var ways int
-	var idx int
+	return races, nil
+}
 
-	for idx < len(paper.Time) {
-		r := result(paper.Time[idx], paper.Distance[idx])
-		rs = append(rs, len(r))
-		idx++
+func reportModel(filename string, m Model) error {
+	paper, err := parse(filename)
+	if err != nil {
+		return err
 	}
 
-	for _, v := range rs {
-		if ways == 0 {
-			ways = v
-			continue
+	for _, combine := range []bool{false, true} {
+		races, err := paper.Races(combine)
+		if err != nil {
+			return err
+		}
+
+		for _, race := range races {
+			if !race.Time.IsInt64() || !race.Distance.IsInt64() {
+				return fmt.Errorf("race of %v ms is too long for physics models", race.Time)
+			}
+
+			ws := m.Winning(int(race.Time.Int64()), int(race.Distance.Int64()))
+			fmt.Printf("time %v record %v: winning holds %v (%d ways)\n", race.Time, race.Distance, ws, Count(ws))
 		}
-		ways *= v
 	}
 
-	return ways
+	return nil
 }
 
-func result(time, distance int) []int {
-	var res []int
-	var i int
+func reportRecords(spec string) error {
+	time, ways, found := strings.Cut(spec, ":")
+	if !found {
+		return fmt.Errorf("invalid race %q: want time:ways", spec)
+	}
 
-	for i < time {
-		d := i * (time - i)
-		if d > distance {
-			res = append(res, i)
-		}
-		i++
+	t, ok := new(big.Int).SetString(time, 10)
+	if !ok {
+		return fmt.Errorf("invalid race time %q", time)
 	}
 
-	return res
+	w, ok := new(big.Int).SetString(ways, 10)
+	if !ok {
+		return fmt.Errorf("invalid race ways %q", ways)
+	}
+
+	r, err := RecordsFor(t, w)
+	if err != nil {
+		return err
+	}
+
+	if r.High == nil {
+		fmt.Printf("records %v and above give %v ways to win a %v ms race\n", r.Low, w, t)
+
+		return nil
+	}
+
+	fmt.Printf("records %v to %v give %v ways to win a %v ms race\n", r.Low, r.High, w, t)
+
+	return nil
 }
 
-func parse(filename string) Paper {
+func parse(filename string) (Paper, error) {
 	fh, err := os.Open(filename)
 	if err != nil {
-		log.Fatal("unable to read file: %w", err)
+		return Paper{}, fmt.Errorf("unable to read file: %w", err)
 	}
+	defer fh.Close()
+
+	return readPaper(filename, fh)
+}
 
+func readPaper(filename string, r io.Reader) (Paper, error) {
 	parser := participle.MustBuild[Paper]()
-	paper, err := parser.Parse(filename, fh)
+
+	paper, err := parser.Parse(filename, r)
 	if err != nil {
-		log.Fatal("unable to parse paper: %w", err)
+		return Paper{}, fmt.Errorf("unable to parse paper: %w", err)
 	}
 
-	return *paper
+	return *paper, nil
 }
 
-func merge(paper Paper) Paper {
-	return Paper{
-		Time:     []int{intsToInt(paper.Time)},
-		Distance: []int{intsToInt(paper.Distance)},
+func concat(ints []int) *big.Int {
+	if len(ints) == 0 {
+		return new(big.Int)
 	}
-}
 
-func intsToInt(ints []int) int {
-	txt := strings.Trim(strings.Join(strings.Fields(fmt.Sprint(ints)), ""), "[]")
+	var sb strings.Builder
 
-	val, err := strconv.Atoi(txt)
-	if err != nil {
-		log.Fatal("unable to convert to int: %w", err)
+	for _, v := range ints {
+		sb.WriteString(strconv.Itoa(v))
 	}
 
+	val, _ := new(big.Int).SetString(sb.String(), 10)
+
 	return val
 }
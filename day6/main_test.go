@@ -0,0 +1,355 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaysToWin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		combine  bool
+		want     string
+	}{
+		{
+			name:     "demo1",
+			filename: "demo1.txt",
+			want:     "288",
+		},
+		{
+			name:     "demo1 combined",
+			filename: "demo1.txt",
+			combine:  true,
+			want:     "71503",
+		},
+		{
+			name:     "input1",
+			filename: "input1.txt",
+			want:     "633080",
+		},
+		{
+			name:     "input1 combined",
+			filename: "input1.txt",
+			combine:  true,
+			want:     "20048741",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := WaysToWin(tt.filename, tt.combine)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestWaysMatchesBruteForce(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(6))
+
+	for i := 0; i < 5000; i++ {
+		time := rng.Intn(200)
+		distance := rng.Intn(time*time/4 + 10)
+
+		assert.Equal(t, bruteWays(time, distance), ways(time, distance), "time %d distance %d", time, distance)
+	}
+
+	for time := 0; time < 60; time++ {
+		for hold := 0; hold <= time; hold++ {
+			tie := hold * (time - hold)
+
+			assert.Equal(t, bruteWays(time, tie), ways(time, tie), "time %d tie %d", time, tie)
+			assert.Equal(t, bruteWays(time, tie-1), ways(time, tie-1), "time %d tie %d", time, tie-1)
+		}
+	}
+}
+
+func TestWaysBig(t *testing.T) {
+	t.Parallel()
+
+	time, _ := new(big.Int).SetString("100000000000000000000", 10)
+	distance, _ := new(big.Int).SetString("999999999900000000000000000000", 10)
+
+	assert.Equal(t, "99999999979999999999", Ways(time, distance).String())
+	assert.Equal(t, "0", Ways(big.NewInt(4), big.NewInt(4)).String())
+	assert.Equal(t, "1", Ways(big.NewInt(4), big.NewInt(3)).String())
+}
+
+func TestRaces(t *testing.T) {
+	t.Parallel()
+
+	paper, err := readPaper("long", strings.NewReader(
+		"Time: 123456789 987654321 123456789 987654321\nDistance: 1 2 3 4\n"))
+	require.NoError(t, err)
+
+	races, err := paper.Races(true)
+	require.NoError(t, err)
+	require.Len(t, races, 1)
+	assert.Equal(t, "123456789987654321123456789987654321", races[0].Time.String())
+	assert.Equal(t, "1234", races[0].Distance.String())
+
+	races, err = paper.Races(false)
+	require.NoError(t, err)
+	assert.Len(t, races, 4)
+
+	_, err = Paper{Time: []int{1, 2}, Distance: []int{3}}.Races(false)
+	assert.Error(t, err)
+
+	_, err = readPaper("bad", strings.NewReader("Time: 7\nSpeed: 9\n"))
+	assert.Error(t, err)
+
+	_, err = WaysToWin("missing.txt", false)
+	assert.Error(t, err)
+}
+
+func TestModelsMatchBruteForce(t *testing.T) {
+	t.Parallel()
+
+	models := []Model{
+		Linear{},
+		QuadraticCharge{},
+		Capped{Top: 1},
+		Capped{Top: 7},
+		Capped{Top: 40},
+		Decay{Drag: 0},
+		Decay{Drag: 1},
+		Decay{Drag: 3},
+		Phases{},
+		Phases{{Duration: 5, Factor: 2}},
+		Phases{{Duration: 3, Factor: 1}, {Duration: 4, Factor: 3}, {Duration: 10, Factor: 0}},
+		Phases{{Duration: 2, Factor: 0}, {Duration: 6, Factor: 5}, {Duration: 1, Factor: 1}},
+	}
+
+	rng := rand.New(rand.NewSource(47))
+
+	for _, m := range models {
+		for i := 0; i < 2000; i++ {
+			time := rng.Intn(80)
+
+			peak := 0
+			for h := 0; h < time; h++ {
+				peak = max(peak, m.Distance(time, h))
+			}
+
+			record := rng.Intn(peak+2) - 1
+
+			assert.Equal(t, bruteWindows(m, time, record), m.Winning(time, record),
+				"%#v time %d record %d", m, time, record)
+		}
+	}
+}
+
+func TestLinearModelMatchesWays(t *testing.T) {
+	t.Parallel()
+
+	ws := Linear{}.Winning(34908986, 204171312101780)
+	assert.Equal(t, []Window{{First: 7430123, Last: 27478863}}, ws)
+	assert.Equal(t, 20048741, Count(ws))
+
+	ws = QuadraticCharge{}.Winning(34908986, 204171312101780)
+	assert.Equal(t, []Window{{First: 2419, Last: 34908985}}, ws)
+}
+
+func TestModelsLongRace(t *testing.T) {
+	t.Parallel()
+
+	models := []Model{
+		Linear{},
+		QuadraticCharge{},
+		Capped{Top: 5},
+		Decay{Drag: 1},
+		Phases{{Duration: 5, Factor: 2}},
+	}
+
+	for _, m := range models {
+		assert.Equal(t, []Window{{First: 1, Last: 9999999999}}, m.Winning(10000000000, 0), "%#v", m)
+	}
+
+	for _, m := range []Model{Linear{}, Capped{Top: 3000000000}, Decay{}, Phases{}} {
+		assert.Equal(t, []Window{{First: 2500000000, Last: 2500000000}}, m.Winning(5000000000, 6249999999999999999), "%#v", m)
+		assert.Equal(t, 6250000000000000000, m.Distance(5000000000, 2500000000), "%#v", m)
+	}
+}
+
+func TestParseModel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		model   string
+		phases  string
+		want    Model
+		wantErr bool
+	}{
+		{name: "linear", model: "linear", want: Linear{}},
+		{name: "quadratic", model: "quadratic", want: QuadraticCharge{}},
+		{name: "capped", model: "capped", want: Capped{Top: 10}},
+		{name: "decay", model: "decay", want: Decay{Drag: 2}},
+		{
+			name:   "phases",
+			model:  "phases",
+			phases: "3:1, 4:2",
+			want:   Phases{{Duration: 3, Factor: 1}, {Duration: 4, Factor: 2}},
+		},
+		{name: "bad phase", model: "phases", phases: "3", wantErr: true},
+		{name: "negative factor", model: "phases", phases: "3:-1", wantErr: true},
+		{name: "unknown", model: "warp", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseModel(tt.model, 10, 2, tt.phases)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRecordsForMatchesBruteForce(t *testing.T) {
+	t.Parallel()
+
+	for time := 1; time < 40; time++ {
+		peak := (time / 2) * (time - time/2)
+
+		for n := 0; n <= time; n++ {
+			var records []int
+
+			for d := 0; d <= peak; d++ {
+				if bruteWays(time, d) == n {
+					records = append(records, d)
+				}
+			}
+
+			got, err := RecordsFor(big.NewInt(int64(time)), big.NewInt(int64(n)))
+			if n == 0 {
+				require.NoError(t, err, "time %d ways %d", time, n)
+				assert.Equal(t, fmt.Sprint(records[0]), got.Low.String(), "time %d ways %d", time, n)
+				assert.Nil(t, got.High, "time %d ways %d", time, n)
+
+				continue
+			}
+
+			if len(records) == 0 {
+				assert.ErrorIs(t, err, ErrUnreachable, "time %d ways %d", time, n)
+
+				continue
+			}
+
+			require.NoError(t, err, "time %d ways %d", time, n)
+			assert.Equal(t, fmt.Sprint(records[0]), got.Low.String(), "time %d ways %d", time, n)
+			assert.Equal(t, fmt.Sprint(records[len(records)-1]), got.High.String(), "time %d ways %d", time, n)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	paper, err := Generate([]Spec{{Time: 7, Ways: 4}, {Time: 15, Ways: 8}, {Time: 30, Ways: 9}})
+	require.NoError(t, err)
+	assert.Equal(t, "Time:      7  15   30\nDistance:  6  36  200\n", paper.String())
+
+	parsed, err := readPaper("generated", strings.NewReader(paper.String()))
+	require.NoError(t, err)
+	assert.Equal(t, paper, parsed)
+
+	races, err := parsed.Races(false)
+	require.NoError(t, err)
+
+	for idx, want := range []string{"4", "8", "9"} {
+		assert.Equal(t, want, Ways(races[idx].Time, races[idx].Distance).String())
+	}
+
+	demo, err := parse("demo1.txt")
+	require.NoError(t, err)
+
+	txt, err := os.ReadFile("demo1.txt")
+	require.NoError(t, err)
+	assert.Equal(t, string(txt), demo.String())
+
+	paper, err = Generate([]Spec{{Time: 7, Ways: 0}})
+	require.NoError(t, err)
+	assert.Equal(t, []int{12}, paper.Distance)
+
+	_, err = RecordsFor(big.NewInt(7), big.NewInt(-1))
+	assert.ErrorIs(t, err, ErrUnreachable)
+
+	_, err = Generate([]Spec{{Time: 7, Ways: 3}})
+	assert.ErrorIs(t, err, ErrUnreachable)
+
+	_, err = Generate([]Spec{{Time: 1 << 33, Ways: 1}})
+	assert.Error(t, err)
+
+	paper, err = Generate([]Spec{{Time: 1 << 31, Ways: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1<<60 - 1}, paper.Distance)
+
+	specs, err := ParseSpecs("7:4, 15:8")
+	require.NoError(t, err)
+	assert.Equal(t, []Spec{{Time: 7, Ways: 4}, {Time: 15, Ways: 8}}, specs)
+
+	_, err = ParseSpecs("7")
+	assert.Error(t, err)
+}
+
+func bruteWindows(m Model, time, record int) []Window {
+	var ws []Window
+
+	for h := 0; h < time; h++ {
+		if m.Distance(time, h) <= record {
+			continue
+		}
+
+		if len(ws) > 0 && ws[len(ws)-1].Last == h-1 {
+			ws[len(ws)-1].Last = h
+
+			continue
+		}
+
+		ws = append(ws, Window{First: h, Last: h})
+	}
+
+	return ws
+}
+
+func ways(time, distance int) int {
+	return int(Ways(big.NewInt(int64(time)), big.NewInt(int64(distance))).Int64())
+}
+
+func bruteWays(time, distance int) int {
+	var n int
+
+	for i := 0; i < time; i++ {
+		if i*(time-i) > distance {
+			n++
+		}
+	}
+
+	return n
+}
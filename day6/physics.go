@@ -0,0 +1,369 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+type Model interface {
+	Distance(time, hold int) int
+	Winning(time, record int) []Window
+}
+
+type Window struct {
+	First int
+	Last  int
+}
+
+type Linear struct{}
+
+type QuadraticCharge struct{}
+
+type Capped struct {
+	Top int
+}
+
+type Decay struct {
+	Drag int
+}
+
+type Phase struct {
+	Duration int
+	Factor   int
+}
+
+type Phases []Phase
+
+func ParseModel(name string, top, drag int, phases string) (Model, error) {
+	switch name {
+	case "linear":
+		return Linear{}, nil
+	case "quadratic":
+		return QuadraticCharge{}, nil
+	case "capped":
+		if top < 1 {
+			return nil, fmt.Errorf("capped model needs a positive top speed: %v", top)
+		}
+
+		return Capped{Top: top}, nil
+	case "decay":
+		if drag < 0 {
+			return nil, fmt.Errorf("decay model needs a non-negative drag: %v", drag)
+		}
+
+		return Decay{Drag: drag}, nil
+	case "phases":
+		return ParsePhases(phases)
+	default:
+		return nil, fmt.Errorf("unknown physics model: %v", name)
+	}
+}
+
+func ParsePhases(txt string) (Phases, error) {
+	var p Phases
+
+	for _, v := range strings.Split(txt, ",") {
+		duration, factor, found := strings.Cut(strings.TrimSpace(v), ":")
+		if !found {
+			return nil, fmt.Errorf("invalid phase %q: want duration:factor", v)
+		}
+
+		d, err := strconv.Atoi(duration)
+		if err != nil || d < 1 {
+			return nil, fmt.Errorf("invalid phase duration %q", v)
+		}
+
+		f, err := strconv.Atoi(factor)
+		if err != nil || f < 0 {
+			return nil, fmt.Errorf("invalid phase factor %q", v)
+		}
+
+		p = append(p, Phase{Duration: d, Factor: f})
+	}
+
+	return p, nil
+}
+
+func (w Window) Count() int {
+	return max(w.Last-w.First+1, 0)
+}
+
+func (w Window) String() string {
+	return fmt.Sprintf("[%d, %d]", w.First, w.Last)
+}
+
+func Count(ws []Window) int {
+	var n int
+
+	for _, w := range ws {
+		n += w.Count()
+	}
+
+	return n
+}
+
+func (Linear) Distance(time, hold int) int {
+	return mul(hold, time-hold)
+}
+
+func (Linear) Winning(time, record int) []Window {
+	return quadratic(1, time, record, 0, time)
+}
+
+func (QuadraticCharge) Distance(time, hold int) int {
+	return mul(mul(hold, hold), time-hold)
+}
+
+func (m QuadraticCharge) Winning(time, record int) []Window {
+	return unimodal(m, time, record)
+}
+
+func (c Capped) Distance(time, hold int) int {
+	return mul(min(hold, c.Top), time-hold)
+}
+
+// Below the cap the boat is the linear model; above it the distance
+// falls by Top for every extra millisecond held.
+func (c Capped) Winning(time, record int) []Window {
+	ws := quadratic(1, time, record, 0, min(c.Top, time))
+	if c.Top >= time {
+		return ws
+	}
+
+	last := min(time-record/c.Top-1, time-1)
+	if last < c.Top {
+		return ws
+	}
+
+	if len(ws) > 0 && ws[0].Last == c.Top-1 {
+		ws[0].Last = last
+
+		return ws
+	}
+
+	return append(ws, Window{First: c.Top, Last: last})
+}
+
+func (d Decay) Distance(time, hold int) int {
+	travel := time - hold
+	if travel <= 0 || hold <= 0 {
+		return 0
+	}
+
+	steps := travel
+	if d.Drag > 0 {
+		steps = min(travel, (hold+d.Drag-1)/d.Drag)
+	}
+
+	n, v := big.NewInt(int64(steps)), big.NewInt(int64(hold))
+	lost := new(big.Int).Mul(n, new(big.Int).Sub(n, one))
+	lost.Mul(lost, big.NewInt(int64(d.Drag))).Rsh(lost, 1)
+
+	return saturate(n.Mul(n, v).Sub(n, lost))
+}
+
+func (d Decay) Winning(time, record int) []Window {
+	return unimodal(d, time, record)
+}
+
+func (p Phases) Distance(time, hold int) int {
+	var travelled, start int
+
+	for _, ph := range p {
+		end := start + ph.Duration
+		if end >= time {
+			break
+		}
+
+		travelled = add(travelled, mul(ph.Factor, max(end-max(start, hold), 0)))
+		start = end
+	}
+
+	travelled = add(travelled, mul(p.factor(max(start, hold)), max(time-max(start, hold), 0)))
+
+	return mul(hold, travelled)
+}
+
+// Within one phase the remaining distance per unit of speed is
+// factor*(end-hold) + rest, so every phase is a quadratic in hold.
+func (p Phases) Winning(time, record int) []Window {
+	var ws []Window
+
+	bounds := []int{0}
+	for _, ph := range p {
+		if end := bounds[len(bounds)-1] + ph.Duration; end < time {
+			bounds = append(bounds, end)
+		}
+	}
+	bounds = append(bounds, time)
+
+	rest := 0
+	for idx := len(bounds) - 2; idx >= 0; idx-- {
+		start, end := bounds[idx], bounds[idx+1]
+		factor := p.factor(start)
+
+		for _, w := range quadratic(factor, add(mul(factor, end), rest), record, start, end) {
+			if len(ws) > 0 && ws[0].First == w.Last+1 {
+				ws[0].First = w.First
+
+				continue
+			}
+
+			ws = append([]Window{w}, ws...)
+		}
+
+		rest = add(rest, mul(factor, end-start))
+	}
+
+	return ws
+}
+
+func (p Phases) factor(t int) int {
+	if len(p) == 0 {
+		return 1
+	}
+
+	for _, ph := range p {
+		if t < ph.Duration {
+			return ph.Factor
+		}
+
+		t -= ph.Duration
+	}
+
+	return p[len(p)-1].Factor
+}
+
+// quadratic returns the holds in [low, high) where hold*(b-a*hold) beats
+// the record.
+func quadratic(a, b, record, low, high int) []Window {
+	wins := func(h int) bool {
+		return mul(h, b-mul(a, h)) > record
+	}
+
+	if low >= high {
+		return nil
+	}
+
+	if a == 0 {
+		first := low
+
+		if record >= 0 {
+			if b <= 0 {
+				return nil
+			}
+
+			first = max(low, record/b+1)
+		}
+
+		if first >= high {
+			return nil
+		}
+
+		return []Window{{First: first, Last: high - 1}}
+	}
+
+	peak := min(max(b/(2*a), low), high-1)
+
+	switch {
+	case wins(peak):
+	case peak+1 < high && wins(peak+1):
+		peak++
+	default:
+		return nil
+	}
+
+	disc := math.Sqrt(math.Max(float64(b)*float64(b)-4*float64(a)*float64(record), 0))
+
+	first := min(max(int((float64(b)-disc)/float64(2*a)), low), peak)
+	for !wins(first) {
+		first++
+	}
+	for first > low && wins(first-1) {
+		first--
+	}
+
+	last := max(min(int((float64(b)+disc)/float64(2*a)), high-1), peak)
+	for !wins(last) {
+		last--
+	}
+	for last+1 < high && wins(last+1) {
+		last++
+	}
+
+	return []Window{{First: first, Last: last}}
+}
+
+func unimodal(m Model, time, record int) []Window {
+	if time <= 0 {
+		return nil
+	}
+
+	lo, hi := 0, time-1
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if m.Distance(time, mid) >= m.Distance(time, mid+1) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	peak := lo
+	if m.Distance(time, peak) <= record {
+		return nil
+	}
+
+	first := search(0, peak, func(h int) bool {
+		return m.Distance(time, h) > record
+	})
+
+	last := search(peak, time, func(h int) bool {
+		return m.Distance(time, h) <= record
+	}) - 1
+
+	return []Window{{First: first, Last: last}}
+}
+
+// search returns the first value in [lo, hi) satisfying ok, or hi.
+func search(lo, hi int, ok func(int) bool) int {
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if ok(mid) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	return lo
+}
+
+// mul, add and saturate clamp non-negative distances at math.MaxInt so
+// long races still compare correctly against any record.
+func mul(a, b int) int {
+	if a > 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+
+	return a * b
+}
+
+func add(a, b int) int {
+	if b > 0 && a > math.MaxInt-b {
+		return math.MaxInt
+	}
+
+	return a + b
+}
+
+func saturate(v *big.Int) int {
+	if !v.IsInt64() {
+		return math.MaxInt
+	}
+
+	return int(v.Int64())
+}
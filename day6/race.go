@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math/big"
+)
+
+var one = big.NewInt(1)
+
+func Ways(time, distance *big.Int) *big.Int {
+	lo, ok := firstWin(time, distance)
+	if !ok {
+		return new(big.Int)
+	}
+
+	hi := new(big.Int).Sub(time, lo)
+	if last := new(big.Int).Sub(time, one); hi.Cmp(last) > 0 {
+		hi = last
+	}
+
+	if hi.Cmp(lo) < 0 {
+		return new(big.Int)
+	}
+
+	return hi.Sub(hi, lo).Add(hi, one)
+}
+
+func firstWin(time, distance *big.Int) (*big.Int, bool) {
+	disc := new(big.Int).Mul(time, time)
+	disc.Sub(disc, new(big.Int).Lsh(distance, 2))
+
+	if disc.Sign() < 0 {
+		return nil, false
+	}
+
+	lo := new(big.Int).Sub(time, disc.Sqrt(disc))
+	lo.Rsh(lo, 1)
+
+	if lo.Sign() < 0 {
+		lo.SetInt64(0)
+	}
+
+	if wins(time, distance, lo) {
+		return lo, true
+	}
+
+	if lo.Add(lo, one); wins(time, distance, lo) {
+		return lo, true
+	}
+
+	return nil, false
+}
+
+func wins(time, distance, hold *big.Int) bool {
+	d := new(big.Int).Sub(time, hold)
+
+	return d.Mul(d, hold).Cmp(distance) > 0
+}
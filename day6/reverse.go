@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var ErrUnreachable = errors.New("no record gives that many ways to win")
+
+// Records is an inclusive range of records; a nil High leaves it
+// unbounded above.
+type Records struct {
+	Low  *big.Int
+	High *big.Int
+}
+
+type Spec struct {
+	Time int
+	Ways int
+}
+
+// RecordsFor returns the inclusive range of records for which exactly ways
+// holds win. Winning holds are always the window [lo, time-lo], so the
+// count fixes lo and the record must sit between the distances of lo-1
+// and lo. No hold beats a record of the peak distance or more, so zero
+// ways gives an unbounded range.
+func RecordsFor(time, ways *big.Int) (Records, error) {
+	if ways.Sign() == 0 {
+		return Records{Low: travel(time, new(big.Int).Rsh(time, 1))}, nil
+	}
+
+	if ways.Sign() < 0 || ways.Cmp(time) >= 0 {
+		return Records{}, fmt.Errorf("%v ways to win a %v ms race: %w", ways, time, ErrUnreachable)
+	}
+
+	lo := new(big.Int).Sub(time, ways)
+	lo.Add(lo, one)
+
+	if lo.Bit(0) != 0 {
+		return Records{}, fmt.Errorf("%v ways to win a %v ms race: %w", ways, time, ErrUnreachable)
+	}
+
+	lo.Rsh(lo, 1)
+
+	return Records{
+		Low:  travel(time, new(big.Int).Sub(lo, one)),
+		High: travel(time, lo).Sub(travel(time, lo), one),
+	}, nil
+}
+
+func travel(time, hold *big.Int) *big.Int {
+	d := new(big.Int).Sub(time, hold)
+
+	return d.Mul(d, hold)
+}
+
+func Generate(specs []Spec) (Paper, error) {
+	var paper Paper
+
+	for _, spec := range specs {
+		records, err := RecordsFor(big.NewInt(int64(spec.Time)), big.NewInt(int64(spec.Ways)))
+		if err != nil {
+			return Paper{}, err
+		}
+
+		if !records.Low.IsInt64() {
+			return Paper{}, fmt.Errorf("record %v for a %v ms race does not fit in a paper", records.Low, spec.Time)
+		}
+
+		paper.Time = append(paper.Time, spec.Time)
+		paper.Distance = append(paper.Distance, int(records.Low.Int64()))
+	}
+
+	return paper, nil
+}
+
+func ParseSpecs(txt string) ([]Spec, error) {
+	var specs []Spec
+
+	for _, v := range strings.Split(txt, ",") {
+		time, ways, found := strings.Cut(strings.TrimSpace(v), ":")
+		if !found {
+			return nil, fmt.Errorf("invalid race %q: want time:ways", v)
+		}
+
+		t, err := strconv.Atoi(time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid race time %q: %w", v, err)
+		}
+
+		w, err := strconv.Atoi(ways)
+		if err != nil {
+			return nil, fmt.Errorf("invalid race ways %q: %w", v, err)
+		}
+
+		specs = append(specs, Spec{Time: t, Ways: w})
+	}
+
+	return specs, nil
+}
+
+func (p Paper) String() string {
+	var times, distances strings.Builder
+
+	times.WriteString("Time:    ")
+	distances.WriteString("Distance:")
+
+	for idx := range p.Time {
+		t, d := strconv.Itoa(p.Time[idx]), strconv.Itoa(p.Distance[idx])
+		width := max(len(t), len(d)) + 2
+
+		fmt.Fprintf(&times, "%*s", width, t)
+		fmt.Fprintf(&distances, "%*s", width, d)
+	}
+
+	return times.String() + "\n" + distances.String() + "\n"
+}
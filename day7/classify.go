@@ -0,0 +1,44 @@
+package main
+
+import (
+	"sort"
+)
+
+type Signature [5]int
+
+var signatures = map[Signature]Type{
+	{5}:             fiveKind,
+	{4, 1}:          fourKind,
+	{3, 2}:          fullHouse,
+	{3, 1, 1}:       threeKind,
+	{2, 2, 1}:       twoPair,
+	{2, 1, 1, 1}:    onePair,
+	{1, 1, 1, 1, 1}: highCard,
+}
+
+// Wild cards always do best joining the most common tame card, so a hand
+// is classified by its sorted card counts with the wilds added to the
+// largest count.
+func Classify(c Cards, wild int) Type {
+	counts := make([]int, 0, len(c))
+	for _, n := range c {
+		counts = append(counts, n)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+
+	if len(counts) == 0 {
+		counts = append(counts, 0)
+	}
+
+	counts[0] += wild
+
+	var sig Signature
+	if len(counts) > len(sig) {
+		return nothing
+	}
+
+	copy(sig[:], counts)
+
+	return signatures[sig]
+}
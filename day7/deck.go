@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type Deck struct {
+	Ranks     string `json:"ranks"`
+	Wild      string `json:"wild"`
+	WildValue int    `json:"wildValue"`
+}
+
+var (
+	StandardDeck = Deck{Ranks: "23456789TJQKA"}
+	JokerDeck    = Deck{Ranks: "23456789TJQKA", Wild: "J", WildValue: 1}
+)
+
+func LoadDeck(filename string) (Deck, error) {
+	var d Deck
+
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return Deck{}, fmt.Errorf("unable to read deck: %w", err)
+	}
+
+	if err := json.Unmarshal(raw, &d); err != nil {
+		return Deck{}, fmt.Errorf("unable to parse deck: %w", err)
+	}
+
+	return d, d.Validate()
+}
+
+func (d Deck) Validate() error {
+	if d.Ranks == "" {
+		return fmt.Errorf("deck has no ranks")
+	}
+
+	seen := make(map[rune]bool)
+
+	for _, r := range d.Ranks {
+		if seen[r] {
+			return fmt.Errorf("deck ranks card %q twice", r)
+		}
+
+		seen[r] = true
+	}
+
+	for _, r := range d.Wild {
+		if !seen[r] {
+			return fmt.Errorf("wild card %q is not in the deck ranks", r)
+		}
+	}
+
+	return nil
+}
+
+func (d Deck) IsWild(card string) bool {
+	return card != "" && strings.Contains(d.Wild, card)
+}
+
+// Ranks are listed weakest first and valued from 2, so the standard deck
+// values 2 through A as 2 through 14.
+func (d Deck) Value(card string) (int, bool) {
+	if d.IsWild(card) {
+		return d.WildValue, true
+	}
+
+	idx := strings.Index(d.Ranks, card)
+	if idx == -1 || len(card) != 1 {
+		return 0, false
+	}
+
+	return idx + 2, true
+}
+
+func (d Deck) tame(c Cards) (Cards, int) {
+	var wild int
+
+	tame := make(Cards, len(c))
+
+	for card, n := range c {
+		if d.IsWild(card) {
+			wild += n
+
+			continue
+		}
+
+		tame[card] = n
+	}
+
+	return tame, wild
+}
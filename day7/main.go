@@ -1,10 +1,10 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 	"sort"
-	"strconv"
 	"strings"
 
 	"github.com/alecthomas/participle/v2"
@@ -23,7 +23,7 @@ type Hand struct {
 	Bid      int `@Int`
 	Type     Type
 	Strength []int
-	Joker    bool
+	Deck     Deck
 }
 
 type Cards map[string]int
@@ -65,15 +65,29 @@ const (
 )
 
 func main() {
-	total1 := TotalWinnings("input1.txt", false)
+	deck := flag.String("deck", "", "JSON deck definition to play instead of the standard and joker decks")
+	flag.Parse()
+
+	if *deck != "" {
+		d, err := LoadDeck(*deck)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Println("Total winnings:", TotalWinnings("input1.txt", d))
+
+		return
+	}
+
+	total1 := TotalWinnings("input1.txt", StandardDeck)
 	log.Println("Part 1: Total winnings:", total1)
 
-	total2 := TotalWinnings("input1.txt", true)
+	total2 := TotalWinnings("input1.txt", JokerDeck)
 	log.Println("Part 2: Total winnings:", total2)
 }
 
-func TotalWinnings(filename string, joker bool) int {
-	hands := parse(filename, joker)
+func TotalWinnings(filename string, deck Deck) int {
+	hands := parse(filename, deck)
 	hands.Sort()
 
 	return hands.Winnings()
@@ -124,199 +138,21 @@ func (h Hands) Winnings() int {
 }
 
 func (h *Hand) Result() {
-	j := h.Jokers()
-
-	switch c := h.Cards; {
-	case isFiveKind(c, j):
-		h.Type = fiveKind
-	case isFourKind(c, j):
-		h.Type = fourKind
-	case isFullHouse(c, j):
-		h.Type = fullHouse
-	case isThreeKind(c, j):
-		h.Type = threeKind
-	case isTwoPair(c, j):
-		h.Type = twoPair
-	case isOnePair(c, j):
-		h.Type = onePair
-	default:
-		h.Type = highCard
-	}
+	h.Type = Classify(h.Deck.tame(h.Cards))
 }
 
 func (h *Hand) Score() {
-	var val int
-
-	cards := strings.Split(h.RawCards, "")
-
-	for _, card := range cards {
-		switch c := string(card); {
-		case c == "A":
-			val = 14
-		case c == "K":
-			val = 13
-		case c == "Q":
-			val = 12
-		case c == "J":
-			switch h.Joker {
-			case true:
-				val = 1
-			default:
-				val = 11
-			}
-		case c == "T":
-			val = 10
-		default:
-			val = toInt(c)
+	for _, card := range strings.Split(h.RawCards, "") {
+		val, ok := h.Deck.Value(card)
+		if !ok {
+			log.Fatalf("unknown card %q in hand %v", card, h.RawCards)
 		}
 
 		h.Strength = append(h.Strength, val)
 	}
 }
 
-func (h *Hand) Jokers() int {
-	if h.Joker {
-		_, ok := h.Cards["J"]
-		if ok {
-			return h.Cards["J"]
-		}
-	}
-
-	return 0
-}
-
-func isOnePair(c Cards, j int) bool {
-	if j > 0 {
-		return true
-	}
-
-	for _, v := range c {
-		if v == 2 {
-			return true
-		}
-	}
-
-	return false
-}
-
-func isTwoPair(c Cards, j int) bool {
-	var res int
-
-	switch {
-	case j == 1:
-		return isOnePair(deleteJoker(c), j-1)
-	case j >= 2:
-		return true
-	}
-
-	for _, v := range c {
-		if v == 2 {
-			res++
-		}
-	}
-
-	return res == 2
-}
-
-func isThreeKind(c Cards, j int) bool {
-	switch {
-	case j == 1:
-		return isOnePair(deleteJoker(c), j-1)
-	case j >= 2:
-		return true
-	}
-
-	for _, v := range c {
-		if v == 3 {
-			return true
-		}
-	}
-
-	return false
-}
-
-func isFullHouse(c Cards, j int) bool {
-	var three, pair bool
-
-	switch {
-	case j == 1:
-		if isThreeKind(deleteJoker(c), j-1) {
-			return true
-		}
-
-		if isTwoPair(deleteJoker(c), j-1) {
-			return true
-		}
-	case j == 2:
-		if isThreeKind(deleteJoker(c), j-2) {
-			return true
-		}
-
-		if isOnePair(deleteJoker(c), j-2) {
-			return true
-		}
-	case j >= 3:
-		return true
-	}
-
-	for _, v := range c {
-		if v == 3 {
-			three = true
-		}
-
-		if v == 2 {
-			pair = true
-		}
-	}
-
-	return three && pair
-}
-
-func isFourKind(c Cards, j int) bool {
-	switch {
-	case j == 1:
-		if isThreeKind(deleteJoker(c), j-1) {
-			return true
-		}
-	case j == 2:
-		if isOnePair(deleteJoker(c), j-2) {
-			return true
-		}
-	case j >= 3:
-		return true
-	}
-
-	for _, v := range c {
-		if v == 4 {
-			return true
-		}
-	}
-
-	return false
-}
-
-func isFiveKind(c Cards, j int) bool {
-	switch {
-	case j == 1:
-		if isFourKind(deleteJoker(c), j-1) {
-			return true
-		}
-	case j == 2:
-		if isThreeKind(deleteJoker(c), j-2) {
-			return true
-		}
-	case j == 3:
-		if isOnePair(deleteJoker(c), j-3) {
-			return true
-		}
-	case j >= 4:
-		return true
-	}
-
-	return len(c) == 1
-}
-
-func parse(filename string, joker bool) Hands {
+func parse(filename string, deck Deck) Hands {
 	var hands Hands
 
 	handLexer := lexer.MustSimple([]lexer.SimpleRule{
@@ -340,7 +176,7 @@ func parse(filename string, joker bool) Hands {
 
 	for _, hand := range data.Hands {
 		hand.Cards = make(map[string]int)
-		hand.Joker = joker
+		hand.Deck = deck
 		hand.Check()
 		hand.Result()
 		hand.Score()
@@ -351,24 +187,9 @@ func parse(filename string, joker bool) Hands {
 	return hands
 }
 
-func toInt(txt string) int {
-	num, err := strconv.Atoi(txt)
-	if err != nil {
-		log.Fatal("unable to convert to int: %w", err)
-	}
-
-	return num
-}
-
 func (h *Hand) Check() {
 	cards := strings.Split(h.RawCards, "")
 	for _, card := range cards {
 		h.Cards[card]++
 	}
 }
-
-func deleteJoker(c Cards) Cards {
-	delete(c, "J")
-
-	return c
-}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTotalWinnings(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		deck     Deck
+		want     int
+	}{
+		{
+			name:     "demo1",
+			filename: "demo1.txt",
+			deck:     StandardDeck,
+			want:     6440,
+		},
+		{
+			name:     "demo1 jokers",
+			filename: "demo1.txt",
+			deck:     JokerDeck,
+			want:     5905,
+		},
+		{
+			name:     "demo1 jacks and tens wild",
+			filename: "demo1.txt",
+			deck:     Deck{Ranks: "23456789TJQKA", Wild: "JT"},
+			want:     6843,
+		},
+		{
+			name:     "input1",
+			filename: "input1.txt",
+			deck:     StandardDeck,
+			want:     253313241,
+		},
+		{
+			name:     "input1 jokers",
+			filename: "input1.txt",
+			deck:     JokerDeck,
+			want:     253362743,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, TotalWinnings(tt.filename, tt.deck))
+		})
+	}
+}
+
+func TestClassifyExhaustive(t *testing.T) {
+	t.Parallel()
+
+	for _, deck := range []Deck{StandardDeck, JokerDeck} {
+		deck := deck
+
+		t.Run(deck.Ranks+" wild "+deck.Wild, func(t *testing.T) {
+			t.Parallel()
+
+			cards := make([]byte, 5)
+
+			for n := 0; n < 371293; n++ {
+				for i, v := 0, n; i < len(cards); i, v = i+1, v/13 {
+					cards[i] = deck.Ranks[v%13]
+				}
+
+				h := Hand{RawCards: string(cards), Cards: make(map[string]int), Deck: deck}
+				h.Check()
+				h.Result()
+
+				if want := referenceType(cards, deck); h.Type != want {
+					t.Fatalf("hand %v: got %v, want %v", h.RawCards, h.Type, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		cards Cards
+		wild  int
+		want  Type
+	}{
+		{name: "all wild", wild: 5, want: fiveKind},
+		{name: "four wild", cards: Cards{"A": 1}, wild: 4, want: fiveKind},
+		{name: "pair and wild", cards: Cards{"A": 2, "K": 1, "Q": 1}, wild: 1, want: threeKind},
+		{name: "two pair and wild", cards: Cards{"A": 2, "K": 2}, wild: 1, want: fullHouse},
+		{name: "high card", cards: Cards{"A": 1, "K": 1, "Q": 1, "J": 1, "T": 1}, want: highCard},
+		{name: "too many cards", cards: Cards{"A": 1, "K": 1, "Q": 1, "J": 1, "T": 1, "9": 1}, want: nothing},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			before := len(tt.cards)
+
+			assert.Equal(t, tt.want, Classify(tt.cards, tt.wild))
+			assert.Len(t, tt.cards, before)
+		})
+	}
+}
+
+func TestLoadDeck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  string
+		want    Deck
+		wantErr bool
+	}{
+		{
+			name:   "jokers",
+			config: `{"ranks": "23456789TJQKA", "wild": "J", "wildValue": 1}`,
+			want:   JokerDeck,
+		},
+		{
+			name:   "aces low",
+			config: `{"ranks": "A23456789TJQK"}`,
+			want:   Deck{Ranks: "A23456789TJQK"},
+		},
+		{
+			name:    "duplicate rank",
+			config:  `{"ranks": "23456789TJQKAA"}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown wild",
+			config:  `{"ranks": "23456789TJQKA", "wild": "X"}`,
+			wantErr: true,
+		},
+		{
+			name:    "no ranks",
+			config:  `{}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			config:  `{"ranks":`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filename := filepath.Join(t.TempDir(), "deck.json")
+			require.NoError(t, os.WriteFile(filename, []byte(tt.config), 0o600))
+
+			got, err := LoadDeck(filename)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDeckValue(t *testing.T) {
+	t.Parallel()
+
+	for card, want := range map[string]int{"2": 2, "9": 9, "T": 10, "J": 11, "A": 14} {
+		got, ok := StandardDeck.Value(card)
+		assert.True(t, ok)
+		assert.Equal(t, want, got, card)
+	}
+
+	got, ok := JokerDeck.Value("J")
+	assert.True(t, ok)
+	assert.Equal(t, 1, got)
+
+	_, ok = StandardDeck.Value("X")
+	assert.False(t, ok)
+}
+
+func referenceType(cards []byte, deck Deck) Type {
+	var subs []byte
+	for _, r := range []byte(deck.Ranks) {
+		if !deck.IsWild(string(r)) {
+			subs = append(subs, r)
+		}
+	}
+
+	var wild []int
+	for i, c := range cards {
+		if deck.IsWild(string(c)) {
+			wild = append(wild, i)
+		}
+	}
+
+	hand := append([]byte(nil), cards...)
+	pick := make([]int, len(wild))
+	best := nothing
+
+	for {
+		for i, idx := range wild {
+			hand[idx] = subs[pick[i]]
+		}
+
+		best = max(best, plainType(hand))
+
+		i := 0
+		for ; i < len(pick); i++ {
+			if pick[i]++; pick[i] < len(subs) {
+				break
+			}
+
+			pick[i] = 0
+		}
+
+		if i == len(pick) {
+			return best
+		}
+	}
+}
+
+func plainType(cards []byte) Type {
+	var counts [256]int
+
+	distinct, most := 0, 0
+
+	for _, c := range cards {
+		if counts[c] == 0 {
+			distinct++
+		}
+
+		counts[c]++
+		most = max(most, counts[c])
+	}
+
+	switch distinct {
+	case 1:
+		return fiveKind
+	case 2:
+		if most == 4 {
+			return fourKind
+		}
+
+		return fullHouse
+	case 3:
+		if most == 3 {
+			return threeKind
+		}
+
+		return twoPair
+	case 4:
+		return onePair
+	default:
+		return highCard
+	}
+}